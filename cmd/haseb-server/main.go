@@ -0,0 +1,74 @@
+// Command haseb-server runs the HTTP OCR service.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	haseb "github.com/awabrh/haseb"
+	"github.com/awabrh/haseb/server"
+	"github.com/awabrh/haseb/tessdata"
+)
+
+// defaultPoolSize is used when HASEB_POOL_SIZE is unset or invalid.
+const defaultPoolSize = 4
+
+func main() {
+	if err := checkEngineBuild(); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	addr := os.Getenv("HASEB_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	langs := languages()
+
+	if err := haseb.CheckTesseractInstallation(); err != nil {
+		log.Fatalf("tesseract check failed: %v", err)
+	}
+
+	tessdataMgr, err := tessdata.NewManager()
+	if err != nil {
+		log.Fatalf("failed to create tessdata manager: %v", err)
+	}
+	if err := tessdataMgr.EnsureLanguages(context.Background(), langs...); err != nil {
+		log.Fatalf("failed to ensure tessdata for %v: %v", langs, err)
+	}
+	if unverified := tessdataMgr.UnverifiedLanguages(); len(unverified) > 0 {
+		log.Printf("WARNING: traineddata for %v was trusted on first use (no known-good checksum was available to verify it against); pass tessdata.WithChecksums to pin explicit hashes", unverified)
+	}
+
+	pool, err := haseb.NewOCRPool(poolSize(), langs...)
+	if err != nil {
+		log.Fatalf("failed to create OCR pool: %v", err)
+	}
+
+	log.Printf("haseb-server listening on %s", addr)
+	if err := server.ListenAndServe(addr, pool); err != nil {
+		log.Fatalf("server error: %v", err)
+	}
+}
+
+// poolSize reads HASEB_POOL_SIZE, falling back to defaultPoolSize when
+// unset or invalid.
+func poolSize() int {
+	size, err := strconv.Atoi(os.Getenv("HASEB_POOL_SIZE"))
+	if err != nil || size <= 0 {
+		return defaultPoolSize
+	}
+	return size
+}
+
+// languages reads a comma-separated HASEB_LANGS, falling back to
+// "eng,ara".
+func languages() []string {
+	raw := os.Getenv("HASEB_LANGS")
+	if raw == "" {
+		return []string{"eng", "ara"}
+	}
+	return strings.Split(raw, ",")
+}
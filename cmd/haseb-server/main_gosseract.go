@@ -0,0 +1,14 @@
+//go:build gosseract
+
+// This file backs checkEngineBuild when haseb-server is built with `-tags
+// gosseract`, mirroring the engine_gosseract.go/engine_nogosseract.go
+// split in the root package. See main_nogosseract.go for the build
+// without the tag.
+package main
+
+// checkEngineBuild reports that this binary was built with the "gosseract"
+// tag, so haseb.NewOCRPool's default engine factory is functional and
+// startup can proceed to use it.
+func checkEngineBuild() error {
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !gosseract
+
+// See main_gosseract.go: this file backs checkEngineBuild when
+// haseb-server is built without the "gosseract" tag. Without it, startup
+// used to fall through to haseb.NewOCRPool and fail deep inside pool
+// construction with "default gosseract engine unavailable"; checking this
+// upfront gives an operator running the plain `go build
+// ./cmd/haseb-server && ./haseb-server` workflow an actionable message
+// instead.
+package main
+
+import "fmt"
+
+// checkEngineBuild reports that this binary has no functional Engine:
+// haseb.NewOCRPool's default factory requires the "gosseract" build tag
+// and a libtesseract/libleptonica toolchain, and cmd/haseb-server doesn't
+// yet expose a flag to select wasmengine.Client instead (see README.md).
+func checkEngineBuild() error {
+	return fmt.Errorf("haseb-server was built without the %q tag, so it has no usable OCR engine; rebuild with `go build -tags gosseract ./cmd/haseb-server` (requires a libtesseract/libleptonica toolchain), see README.md", "gosseract")
+}
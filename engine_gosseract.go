@@ -0,0 +1,46 @@
+//go:build gosseract
+
+// This file backs the default Engine with the CGo gosseract client and is
+// only compiled with `-tags gosseract`, since gosseract requires a
+// libtesseract/libleptonica toolchain; plain `go build` (and callers only
+// using wasmengine.Client via WithEngineFactory) don't need it. See
+// engine_nogosseract.go for the build without the tag.
+package haseb
+
+import "github.com/otiai10/gosseract/v2"
+
+// defaultEngineFactory builds the CGo gosseract engine.
+func defaultEngineFactory() (Engine, error) {
+	return &gosseractEngine{gosseract.NewClient()}, nil
+}
+
+// gosseractEngine adapts *gosseract.Client's WordBoxProvider method to the
+// package's engine-agnostic []Word, so gosseract.BoundingBox (and the CGo
+// toolchain it requires to even reference) stays confined to this file.
+type gosseractEngine struct {
+	*gosseract.Client
+}
+
+// GetBoundingBoxes implements WordBoxProvider.
+func (e *gosseractEngine) GetBoundingBoxes() ([]Word, error) {
+	boxes, err := e.Client.GetBoundingBoxes(gosseract.RIL_WORD)
+	if err != nil {
+		return nil, err
+	}
+	words := make([]Word, 0, len(boxes))
+	for _, box := range boxes {
+		words = append(words, Word{
+			Text:       box.Word,
+			Confidence: box.Confidence,
+			BBox: BBox{
+				X: box.Box.Min.X,
+				Y: box.Box.Min.Y,
+				W: box.Box.Dx(),
+				H: box.Box.Dy(),
+			},
+			Line:  box.LineNum,
+			Block: box.BlockNum,
+		})
+	}
+	return words, nil
+}
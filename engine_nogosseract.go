@@ -0,0 +1,17 @@
+//go:build !gosseract
+
+// See engine_gosseract.go: this file backs defaultEngineFactory when the
+// package is built without the "gosseract" tag, so the package (and any
+// caller only using wasmengine.Client via WithEngineFactory) builds
+// without a libtesseract/libleptonica toolchain.
+package haseb
+
+import "fmt"
+
+// defaultEngineFactory reports that the CGo gosseract engine wasn't
+// compiled in. Build with `-tags gosseract` (and a
+// libtesseract/libleptonica toolchain available) to use it, or pass
+// WithEngineFactory an alternative such as wasmengine.Client.
+func defaultEngineFactory() (Engine, error) {
+	return nil, fmt.Errorf("default gosseract engine unavailable: built without the %q tag (requires a libtesseract/libleptonica toolchain); pass WithEngineFactory an alternative such as wasmengine.Client", "gosseract")
+}
@@ -1,13 +1,27 @@
-package main
+// Package haseb provides thread-safe OCR processing backed by Tesseract,
+// including a worker pool for concurrent requests and a video frame
+// pipeline; see cmd/haseb-server for the HTTP service built on top of it.
+package haseb
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/otiai10/gosseract/v2"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+
+	"github.com/awabrh/haseb/preproc"
 )
 
 // OCRResult represents the structured output from OCR processing
@@ -18,26 +32,159 @@ type OCRResult struct {
 	Error          string    `json:"error,omitempty"`
 }
 
+// Engine is the OCR backend used by an OCRClient. The CGo-based gosseract
+// client satisfies it directly (see engine_gosseract.go); package
+// wasmengine provides a pure-Go alternative that avoids a
+// libtesseract/libleptonica toolchain entirely.
+type Engine interface {
+	SetImage(imagePath string) error
+	SetImageFromBytes(data []byte) error
+	SetLanguage(langs ...string) error
+	Text() (string, error)
+	HOCRText() (string, error)
+	Close() error
+}
+
+// EngineFactory constructs a fresh Engine, e.g. for each OCRPool worker.
+type EngineFactory func() (Engine, error)
+
+// WordBoxProvider is implemented by engines that can report word-level
+// bounding boxes. Only the CGo gosseract engine does today (see
+// engine_gosseract.go); callers using an engine that doesn't implement it
+// get a clear error instead of a panic.
+type WordBoxProvider interface {
+	GetBoundingBoxes() ([]Word, error)
+}
+
 // OCRClient provides thread-safe OCR operations
 type OCRClient struct {
-	client *gosseract.Client
-	mutex  sync.Mutex
+	client        Engine
+	mutex         sync.Mutex
+	preprocessors preproc.Pipeline
+	langs         []string
+}
+
+// boundingBoxes returns word-level bounding boxes from the client's
+// engine, if it supports them.
+func (c *OCRClient) boundingBoxes() ([]Word, error) {
+	provider, ok := c.client.(WordBoxProvider)
+	if !ok {
+		return nil, fmt.Errorf("word-level bounding boxes are not supported by this engine")
+	}
+	return provider.GetBoundingBoxes()
+}
+
+// WithPreprocessors configures stages to run on each image, in order,
+// before it is handed to Tesseract. It returns c for chaining.
+func (c *OCRClient) WithPreprocessors(stages ...preproc.Stage) *OCRClient {
+	c.preprocessors = stages
+	return c
+}
+
+// preprocess runs the configured pipeline (if any) over imageData and
+// re-encodes the result as PNG. With no preprocessors configured it
+// returns imageData unchanged.
+func (c *OCRClient) preprocess(imageData []byte) ([]byte, error) {
+	return preprocessImage(c.preprocessors, imageData)
+}
+
+// Option configures a new OCRClient.
+type Option func(*ocrClientConfig)
+
+// ocrClientConfig holds the settings a NewOCRClient call assembles from
+// its Options.
+type ocrClientConfig struct {
+	engineFactory EngineFactory
+	langs         []string
+}
+
+// WithEngineFactory selects the Engine implementation NewOCRClient builds,
+// e.g. to use the WASM engine on platforms without a libtesseract
+// toolchain. Defaults to the CGo gosseract engine.
+func WithEngineFactory(factory EngineFactory) Option {
+	return func(c *ocrClientConfig) {
+		c.engineFactory = factory
+	}
+}
+
+// WithLanguages overrides the languages NewOCRClient sets on the engine
+// it builds. Defaults to "eng", "ara". A custom EngineFactory (e.g. one
+// building a wasmengine.Client) must have staged traineddata for every
+// language passed here.
+func WithLanguages(langs ...string) Option {
+	return func(c *ocrClientConfig) {
+		c.langs = langs
+	}
 }
 
 // NewOCRClient creates a new OCR client
-func NewOCRClient() (*OCRClient, error) {
-	client := gosseract.NewClient()
-	err := client.SetLanguage("eng", "ara")
+func NewOCRClient(opts ...Option) (*OCRClient, error) {
+	cfg := ocrClientConfig{
+		engineFactory: defaultEngineFactory,
+		langs:         []string{"eng", "ara"},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	engine, err := cfg.engineFactory()
 	if err != nil {
+		return nil, fmt.Errorf("failed to create engine: %v", err)
+	}
+	if err := engine.SetLanguage(cfg.langs...); err != nil {
 		return nil, fmt.Errorf("failed to set language: %v", err)
 	}
 
 	return &OCRClient{
-		client: client,
+		client: engine,
 		mutex:  sync.Mutex{},
+		langs:  cfg.langs,
 	}, nil
 }
 
+// SetLanguages changes the languages used for subsequent OCR calls on this
+// client, e.g. to honor a per-request override.
+func (c *OCRClient) SetLanguages(langs ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.client.SetLanguage(langs...); err != nil {
+		return fmt.Errorf("failed to set language: %v", err)
+	}
+	c.langs = langs
+	return nil
+}
+
+// setImageFromPath validates imagePath, runs it through c.preprocessors (if
+// any), and selects it as the engine's current image. Every ProcessImage*
+// method that takes a path routes through this so none of them silently
+// skip WithPreprocessors.
+func (c *OCRClient) setImageFromPath(imagePath string) error {
+	if !isImageFile(imagePath) {
+		return fmt.Errorf("invalid image file: %s", imagePath)
+	}
+
+	if len(c.preprocessors) == 0 {
+		if err := c.client.SetImage(imagePath); err != nil {
+			return fmt.Errorf("failed to set image: %v", err)
+		}
+		return nil
+	}
+
+	raw, err := os.ReadFile(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %v", err)
+	}
+	processed, err := c.preprocess(raw)
+	if err != nil {
+		return err
+	}
+	if err := c.client.SetImageFromBytes(processed); err != nil {
+		return fmt.Errorf("failed to set preprocessed image: %v", err)
+	}
+	return nil
+}
+
 // ProcessImage performs OCR on the given image file
 func (c *OCRClient) ProcessImage(imagePath string) (*OCRResult, error) {
 	start := time.Now()
@@ -46,14 +193,8 @@ func (c *OCRClient) ProcessImage(imagePath string) (*OCRResult, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Validate file exists and is an image
-	if !isImageFile(imagePath) {
-		return nil, fmt.Errorf("invalid image file: %s", imagePath)
-	}
-
-	// Set the image file
-	if err := c.client.SetImage(imagePath); err != nil {
-		return nil, fmt.Errorf("failed to set image: %v", err)
+	if err := c.setImageFromPath(imagePath); err != nil {
+		return nil, err
 	}
 
 	// Perform OCR
@@ -78,7 +219,12 @@ func (c *OCRClient) ProcessImageBytes(imageData []byte) (*OCRResult, error) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if err := c.client.SetImageFromBytes(imageData); err != nil {
+	processed, err := c.preprocess(imageData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.client.SetImageFromBytes(processed); err != nil {
 		return nil, fmt.Errorf("failed to set image from bytes: %v", err)
 	}
 
@@ -96,6 +242,249 @@ func (c *OCRClient) ProcessImageBytes(imageData []byte) (*OCRResult, error) {
 	}, nil
 }
 
+// OutputFormat selects the representation an OCR call should produce.
+type OutputFormat int
+
+const (
+	FormatPlain OutputFormat = iota
+	FormatHOCR
+	FormatALTO
+	FormatTSV
+	FormatPDF
+)
+
+// BBox is a word's pixel bounding box within the source image.
+type BBox struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Word is a single recognized word with its confidence, position, and
+// place in the page layout.
+type Word struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+	BBox       BBox    `json:"bbox"`
+	Line       int     `json:"line"`
+	Block      int     `json:"block"`
+}
+
+// DetailedResult is the word-level counterpart to OCRResult.
+type DetailedResult struct {
+	Words          []Word    `json:"words"`
+	ProcessingTime float64   `json:"processing_time"`
+	Timestamp      time.Time `json:"timestamp"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// ProcessImageHOCR performs OCR on imagePath and returns the result as
+// hOCR markup in OCRResult.Text.
+func (c *OCRClient) ProcessImageHOCR(imagePath string) (*OCRResult, error) {
+	start := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.setImageFromPath(imagePath); err != nil {
+		return nil, err
+	}
+
+	hocr, err := c.client.HOCRText()
+	if err != nil {
+		return nil, fmt.Errorf("hOCR OCR failed: %v", err)
+	}
+
+	return &OCRResult{
+		Text:           hocr,
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}, nil
+}
+
+// ProcessImageTSV performs OCR on imagePath and returns Tesseract-style TSV
+// (one row per recognized word) in OCRResult.Text.
+func (c *OCRClient) ProcessImageTSV(imagePath string) (*OCRResult, error) {
+	start := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.setImageFromPath(imagePath); err != nil {
+		return nil, err
+	}
+
+	words, err := c.boundingBoxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bounding boxes: %v", err)
+	}
+
+	return &OCRResult{
+		Text:           wordsToTSV(words),
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}, nil
+}
+
+// wordsToTSV renders word-level bounding boxes as TSV matching the columns
+// produced by `tesseract --tsv`.
+func wordsToTSV(words []Word) string {
+	var b strings.Builder
+	b.WriteString("level\tblock_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n")
+	for i, w := range words {
+		fmt.Fprintf(&b, "5\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%.2f\t%s\n",
+			w.Block, w.Line, i+1,
+			w.BBox.X, w.BBox.Y, w.BBox.W, w.BBox.H,
+			w.Confidence, w.Text)
+	}
+	return b.String()
+}
+
+// wordsToALTO renders word-level bounding boxes as a minimal ALTO XML
+// document, enough for downstream consumers that only need word geometry
+// (e.g. PDF text-layer overlays).
+func wordsToALTO(words []Word) string {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	b.WriteString("<alto xmlns=\"http://www.loc.gov/standards/alto/ns-v4#\">\n")
+	b.WriteString("  <Layout>\n    <Page>\n      <PrintSpace>\n")
+	for i, w := range words {
+		fmt.Fprintf(&b, "        <String ID=\"w%d\" CONTENT=%q HPOS=\"%d\" VPOS=\"%d\" WIDTH=\"%d\" HEIGHT=\"%d\" WC=\"%.2f\"/>\n",
+			i, w.Text, w.BBox.X, w.BBox.Y, w.BBox.W, w.BBox.H, w.Confidence)
+	}
+	b.WriteString("      </PrintSpace>\n    </Page>\n  </Layout>\n</alto>\n")
+	return b.String()
+}
+
+// ProcessImageDetailed performs OCR on imagePath and returns word-level
+// text, confidence, bounding box, and layout position.
+func (c *OCRClient) ProcessImageDetailed(imagePath string) (*DetailedResult, error) {
+	start := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.setImageFromPath(imagePath); err != nil {
+		return nil, err
+	}
+
+	words, err := c.boundingBoxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bounding boxes: %v", err)
+	}
+
+	return &DetailedResult{
+		Words:          words,
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}, nil
+}
+
+// ProcessImageAs performs OCR on imagePath and returns the result encoded
+// as format. PDF output is produced by shelling out to the tesseract
+// binary (a searchable PDF isn't available through the Tesseract C API)
+// and is returned base64-encoded in OCRResult.Text.
+func (c *OCRClient) ProcessImageAs(imagePath string, format OutputFormat) (*OCRResult, error) {
+	switch format {
+	case FormatHOCR:
+		return c.ProcessImageHOCR(imagePath)
+	case FormatTSV:
+		return c.ProcessImageTSV(imagePath)
+	case FormatALTO:
+		return c.processImageALTO(imagePath)
+	case FormatPDF:
+		return c.processImagePDF(imagePath)
+	default:
+		return c.ProcessImage(imagePath)
+	}
+}
+
+// processImageALTO performs OCR on imagePath and returns ALTO XML in
+// OCRResult.Text.
+func (c *OCRClient) processImageALTO(imagePath string) (*OCRResult, error) {
+	start := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err := c.setImageFromPath(imagePath); err != nil {
+		return nil, err
+	}
+
+	words, err := c.boundingBoxes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bounding boxes: %v", err)
+	}
+
+	return &OCRResult{
+		Text:           wordsToALTO(words),
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}, nil
+}
+
+// processImagePDF runs the tesseract CLI to produce a searchable PDF from
+// imagePath, returning its bytes base64-encoded in OCRResult.Text. Like
+// every other ProcessImage* path, it routes imagePath through
+// c.preprocessors (if any) before handing it to tesseract, and recognizes
+// using c.langs (set by NewOCRClient/WithLanguages or SetLanguages) rather
+// than tesseract's own default.
+func (c *OCRClient) processImagePDF(imagePath string) (*OCRResult, error) {
+	start := time.Now()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !isImageFile(imagePath) {
+		return nil, fmt.Errorf("invalid image file: %s", imagePath)
+	}
+
+	outDir, err := os.MkdirTemp("", "haseb-pdf-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	tesseractInput := imagePath
+	if len(c.preprocessors) > 0 {
+		raw, err := os.ReadFile(imagePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image: %v", err)
+		}
+		processed, err := c.preprocess(raw)
+		if err != nil {
+			return nil, err
+		}
+		tesseractInput = filepath.Join(outDir, "in.png")
+		if err := os.WriteFile(tesseractInput, processed, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write preprocessed image: %v", err)
+		}
+	}
+
+	outBase := filepath.Join(outDir, "out")
+	args := []string{tesseractInput, outBase}
+	if len(c.langs) > 0 {
+		args = append(args, "-l", strings.Join(c.langs, "+"))
+	}
+	args = append(args, "pdf")
+	cmd := exec.Command("tesseract", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("tesseract pdf output failed: %v: %s", err, output)
+	}
+
+	pdfBytes, err := os.ReadFile(outBase + ".pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read generated PDF: %v", err)
+	}
+
+	return &OCRResult{
+		Text:           base64.StdEncoding.EncodeToString(pdfBytes),
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}, nil
+}
+
 // isImageFile checks if the file is a valid image
 func isImageFile(path string) bool {
 	validExtensions := map[string]bool{
@@ -116,6 +505,241 @@ func (c *OCRClient) Close() error {
 	return c.client.Close()
 }
 
+// OCRJob describes a unit of OCR work submitted to an OCRPool. Exactly one
+// of ImagePath or ImageData should be set. Langs, if non-empty, overrides
+// the pool's default languages for this job only.
+type OCRJob struct {
+	ImagePath string
+	ImageData []byte
+	Langs     []string
+}
+
+// ocrRequest pairs a submitted job with the channel its result is delivered on.
+type ocrRequest struct {
+	job    OCRJob
+	result chan *OCRResult
+}
+
+// OCRPool dispatches OCR jobs across a fixed number of Engine workers so
+// concurrent callers don't serialize on a single Tesseract engine.
+type OCRPool struct {
+	jobs          chan ocrRequest
+	wg            sync.WaitGroup
+	mutex         sync.Mutex
+	closed        bool
+	defaultLangs  []string
+	preprocessors preproc.Pipeline
+	// submitWG tracks Submit calls that have been admitted (passed the
+	// closed check) but haven't sent on jobs yet, so Shutdown can wait for
+	// them to finish before closing the channel they're about to send on.
+	submitWG sync.WaitGroup
+}
+
+// WithPreprocessors configures stages to run on each job's image, in order,
+// before it is handed to Tesseract. It returns p for chaining.
+func (p *OCRPool) WithPreprocessors(stages ...preproc.Stage) *OCRPool {
+	p.preprocessors = stages
+	return p
+}
+
+// preprocessImage runs pipeline (if any) over imageData and re-encodes the
+// result as PNG. With an empty pipeline it returns imageData unchanged. This
+// is the same logic as OCRClient.preprocess, shared so OCRPool-dispatched
+// jobs get the same treatment as OCRClient's direct ProcessImage* calls.
+func preprocessImage(pipeline preproc.Pipeline, imageData []byte) ([]byte, error) {
+	if len(pipeline) == 0 {
+		return imageData, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image for preprocessing: %v", err)
+	}
+
+	img, err = pipeline.Run(img)
+	if err != nil {
+		return nil, fmt.Errorf("preprocessing failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to re-encode preprocessed image: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewOCRPool starts size worker goroutines, each backed by its own Engine
+// (built by the default gosseract-backed EngineFactory) configured with
+// langs (defaulting to "eng", "ara").
+func NewOCRPool(size int, langs ...string) (*OCRPool, error) {
+	return NewOCRPoolWithFactory(size, defaultEngineFactory, langs...)
+}
+
+// NewOCRPoolWithFactory is NewOCRPool with an injectable EngineFactory, e.g.
+// a wasmengine.Client factory, or a fake Engine so callers in other
+// packages (such as package server's tests) can exercise an OCRPool
+// without a real Tesseract install.
+func NewOCRPoolWithFactory(size int, factory EngineFactory, langs ...string) (*OCRPool, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("pool size must be positive, got %d", size)
+	}
+	if len(langs) == 0 {
+		langs = []string{"eng", "ara"}
+	}
+
+	engines := make([]Engine, 0, size)
+	for i := 0; i < size; i++ {
+		engine, err := factory()
+		if err != nil {
+			for _, e := range engines {
+				e.Close()
+			}
+			return nil, fmt.Errorf("failed to create engine for worker %d: %v", i, err)
+		}
+		if err := engine.SetLanguage(langs...); err != nil {
+			engine.Close()
+			for _, e := range engines {
+				e.Close()
+			}
+			return nil, fmt.Errorf("failed to set language for worker %d: %v", i, err)
+		}
+		engines = append(engines, engine)
+	}
+
+	pool := &OCRPool{
+		jobs:         make(chan ocrRequest, size*4),
+		defaultLangs: langs,
+	}
+	for _, engine := range engines {
+		pool.wg.Add(1)
+		go pool.worker(engine)
+	}
+	return pool, nil
+}
+
+// worker processes jobs off the shared channel until it is closed, then
+// releases its engine.
+func (p *OCRPool) worker(engine Engine) {
+	defer p.wg.Done()
+	defer engine.Close()
+
+	for req := range p.jobs {
+		req.result <- runOCRJob(engine, req.job, p.defaultLangs, p.preprocessors)
+	}
+}
+
+// runOCRJob performs OCR for job using engine, mirroring the logic of
+// OCRClient.ProcessImage / ProcessImageBytes, including running pipeline
+// over the image before handing it to Tesseract. If job.Langs overrides the
+// pool's default languages, the engine's language is restored to
+// defaultLangs afterward so the next job on this worker isn't affected. If
+// that restore fails, the worker would otherwise keep misrouting every
+// later job to job.Langs with no indication why, so the failure is
+// surfaced on result.Error instead of being discarded.
+func runOCRJob(engine Engine, job OCRJob, defaultLangs []string, pipeline preproc.Pipeline) (result *OCRResult) {
+	start := time.Now()
+
+	if len(job.Langs) > 0 {
+		if err := engine.SetLanguage(job.Langs...); err != nil {
+			return &OCRResult{Error: fmt.Sprintf("failed to set language: %v", err)}
+		}
+		defer func() {
+			if err := engine.SetLanguage(defaultLangs...); err != nil {
+				msg := fmt.Sprintf("failed to restore worker language to %v after job (worker may misroute subsequent jobs): %v", defaultLangs, err)
+				if result.Error != "" {
+					result.Error = result.Error + "; " + msg
+				} else {
+					result.Error = msg
+				}
+			}
+		}()
+	}
+
+	var imageData []byte
+	if job.ImageData != nil {
+		imageData = job.ImageData
+	} else if isImageFile(job.ImagePath) {
+		data, err := os.ReadFile(job.ImagePath)
+		if err != nil {
+			return &OCRResult{Error: fmt.Sprintf("failed to read image: %v", err)}
+		}
+		imageData = data
+	} else {
+		return &OCRResult{Error: fmt.Sprintf("invalid image file: %s", job.ImagePath)}
+	}
+
+	processed, err := preprocessImage(pipeline, imageData)
+	if err != nil {
+		return &OCRResult{Error: err.Error()}
+	}
+	if err := engine.SetImageFromBytes(processed); err != nil {
+		return &OCRResult{Error: fmt.Sprintf("failed to set image: %v", err)}
+	}
+
+	text, err := engine.Text()
+	if err != nil {
+		return &OCRResult{Error: fmt.Sprintf("OCR failed: %v", err)}
+	}
+
+	return &OCRResult{
+		Text:           text,
+		ProcessingTime: time.Since(start).Seconds(),
+		Timestamp:      time.Now(),
+	}
+}
+
+// Submit enqueues job and returns a channel that receives its single
+// *OCRResult once a worker picks it up. It blocks until the job is queued,
+// the pool is closed, or ctx is done.
+func (p *OCRPool) Submit(ctx context.Context, job OCRJob) (<-chan *OCRResult, error) {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil, fmt.Errorf("OCRPool is closed")
+	}
+	p.submitWG.Add(1)
+	p.mutex.Unlock()
+	defer p.submitWG.Done()
+
+	req := ocrRequest{job: job, result: make(chan *OCRResult, 1)}
+	select {
+	case p.jobs <- req:
+		return req.result, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Shutdown stops accepting new jobs, waits for in-flight work to drain (or
+// ctx to expire), and closes every worker's client. It waits for any
+// Submit call that was admitted before closed was set to finish sending
+// before closing jobs, so Submit never sends on a closed channel.
+func (p *OCRPool) Shutdown(ctx context.Context) error {
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mutex.Unlock()
+
+	p.submitWG.Wait()
+	close(p.jobs)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // CheckTesseractInstallation verifies if tesseract is installed
 func CheckTesseractInstallation() error {
 	_, err := exec.LookPath("tesseract")
@@ -0,0 +1,122 @@
+package haseb
+
+import (
+	"strings"
+	"testing"
+)
+
+// testWords is a small fixture shared by the formatter and
+// ProcessImage*/ProcessImageDetailed tests below.
+var testWords = []Word{
+	{Text: "Hello", Confidence: 95.5, BBox: BBox{X: 1, Y: 2, W: 30, H: 10}, Line: 0, Block: 0},
+	{Text: "world", Confidence: 88.25, BBox: BBox{X: 35, Y: 2, W: 28, H: 10}, Line: 0, Block: 0},
+}
+
+// fakeWordBoxEngine extends fakeEngine with GetBoundingBoxes, so it
+// satisfies WordBoxProvider for tests that exercise the TSV/ALTO/detailed
+// formatters without a real Tesseract install.
+type fakeWordBoxEngine struct {
+	fakeEngine
+	words []Word
+}
+
+func (f *fakeWordBoxEngine) GetBoundingBoxes() ([]Word, error) {
+	return f.words, nil
+}
+
+func TestWordsToTSV(t *testing.T) {
+	got := wordsToTSV(testWords)
+
+	lines := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	if len(lines) != len(testWords)+1 {
+		t.Fatalf("got %d lines, want %d (header + %d words)", len(lines), len(testWords)+1, len(testWords))
+	}
+	if lines[0] != "level\tblock_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext" {
+		t.Fatalf("header = %q", lines[0])
+	}
+	want := "5\t0\t0\t1\t1\t2\t30\t10\t95.50\tHello"
+	if lines[1] != want {
+		t.Fatalf("row 1 = %q, want %q", lines[1], want)
+	}
+}
+
+func TestWordsToALTO(t *testing.T) {
+	got := wordsToALTO(testWords)
+
+	if !strings.Contains(got, `<alto xmlns="http://www.loc.gov/standards/alto/ns-v4#">`) {
+		t.Fatalf("missing ALTO root element: %s", got)
+	}
+	want := `<String ID="w0" CONTENT="Hello" HPOS="1" VPOS="2" WIDTH="30" HEIGHT="10" WC="95.50"/>`
+	if !strings.Contains(got, want) {
+		t.Fatalf("missing word 0 string element, got: %s", got)
+	}
+	if strings.Count(got, "<String ") != len(testWords) {
+		t.Fatalf("got %d <String> elements, want %d", strings.Count(got, "<String "), len(testWords))
+	}
+}
+
+func newFakeWordBoxClient(t *testing.T, words []Word) *OCRClient {
+	t.Helper()
+	client, err := NewOCRClient(WithEngineFactory(func() (Engine, error) {
+		return &fakeWordBoxEngine{words: words}, nil
+	}))
+	if err != nil {
+		t.Fatalf("NewOCRClient: %v", err)
+	}
+	return client
+}
+
+func TestProcessImageTSV(t *testing.T) {
+	client := newFakeWordBoxClient(t, testWords)
+
+	result, err := client.ProcessImageTSV("testdata/scan.png")
+	if err != nil {
+		t.Fatalf("ProcessImageTSV: %v", err)
+	}
+	if !strings.Contains(result.Text, "Hello") || !strings.Contains(result.Text, "world") {
+		t.Fatalf("Text = %q, want it to contain both recognized words", result.Text)
+	}
+}
+
+func TestProcessImageASFormatALTO(t *testing.T) {
+	client := newFakeWordBoxClient(t, testWords)
+
+	result, err := client.ProcessImageAs("testdata/scan.png", FormatALTO)
+	if err != nil {
+		t.Fatalf("ProcessImageAs(FormatALTO): %v", err)
+	}
+	if !strings.Contains(result.Text, "<alto") {
+		t.Fatalf("Text = %q, want ALTO XML", result.Text)
+	}
+}
+
+func TestProcessImageDetailed(t *testing.T) {
+	client := newFakeWordBoxClient(t, testWords)
+
+	result, err := client.ProcessImageDetailed("testdata/scan.png")
+	if err != nil {
+		t.Fatalf("ProcessImageDetailed: %v", err)
+	}
+	if len(result.Words) != len(testWords) {
+		t.Fatalf("got %d words, want %d", len(result.Words), len(testWords))
+	}
+	if result.Words[0].Text != "Hello" || result.Words[0].BBox.W != 30 {
+		t.Fatalf("Words[0] = %+v, want it to match the fixture", result.Words[0])
+	}
+}
+
+// TestBoundingBoxesRequiresWordBoxProvider pins that an engine without
+// GetBoundingBoxes (e.g. the plain fakeEngine, or wasmengine.Client) gets a
+// clear error from the TSV/ALTO/detailed endpoints instead of a panic.
+func TestBoundingBoxesRequiresWordBoxProvider(t *testing.T) {
+	client, err := NewOCRClient(WithEngineFactory(func() (Engine, error) {
+		return &fakeEngine{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("NewOCRClient: %v", err)
+	}
+
+	if _, err := client.ProcessImageTSV("testdata/scan.png"); err == nil {
+		t.Fatal("expected ProcessImageTSV to fail for an engine without GetBoundingBoxes")
+	}
+}
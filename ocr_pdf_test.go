@@ -0,0 +1,92 @@
+package haseb
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeTesseractCLI writes a stand-in "tesseract" script to a temp bin dir,
+// prepends it to PATH, and returns the path to the file it records its
+// invocation args to. It lets processImagePDF's exec.Command("tesseract",
+// ...) be exercised without a real Tesseract install.
+func fakeTesseractCLI(t *testing.T) (argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake tesseract CLI script is POSIX shell only")
+	}
+
+	binDir := t.TempDir()
+	argsFile = filepath.Join(binDir, "args.txt")
+	script := "#!/bin/sh\n" +
+		"echo \"$@\" > " + argsFile + "\n" +
+		"out=\"$2\"\n" +
+		"printf '%%PDF-fake' > \"$out.pdf\"\n"
+	if err := os.WriteFile(filepath.Join(binDir, "tesseract"), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake tesseract: %v", err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestProcessImagePDFPassesConfiguredLanguages(t *testing.T) {
+	argsFile := fakeTesseractCLI(t)
+
+	client, err := NewOCRClient(WithEngineFactory(func() (Engine, error) {
+		return &fakeEngine{}, nil
+	}), WithLanguages("ara"))
+	if err != nil {
+		t.Fatalf("NewOCRClient: %v", err)
+	}
+
+	result, err := client.ProcessImageAs("testdata/scan.png", FormatPDF)
+	if err != nil {
+		t.Fatalf("ProcessImageAs(FormatPDF): %v", err)
+	}
+
+	pdfBytes, err := base64.StdEncoding.DecodeString(result.Text)
+	if err != nil {
+		t.Fatalf("result.Text is not base64: %v", err)
+	}
+	if string(pdfBytes) != "%PDF-fake" {
+		t.Fatalf("decoded PDF = %q, want %q", pdfBytes, "%PDF-fake")
+	}
+
+	recordedArgs, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("failed to read recorded tesseract args: %v", err)
+	}
+	if !strings.Contains(string(recordedArgs), "-l ara") {
+		t.Fatalf("tesseract args = %q, want them to contain %q", recordedArgs, "-l ara")
+	}
+}
+
+func TestProcessImagePDFHonorsSetLanguages(t *testing.T) {
+	argsFile := fakeTesseractCLI(t)
+
+	client, err := NewOCRClient(WithEngineFactory(func() (Engine, error) {
+		return &fakeEngine{}, nil
+	}))
+	if err != nil {
+		t.Fatalf("NewOCRClient: %v", err)
+	}
+	if err := client.SetLanguages("eng"); err != nil {
+		t.Fatalf("SetLanguages: %v", err)
+	}
+
+	if _, err := client.ProcessImageAs("testdata/scan.png", FormatPDF); err != nil {
+		t.Fatalf("ProcessImageAs(FormatPDF): %v", err)
+	}
+
+	recordedArgs, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("failed to read recorded tesseract args: %v", err)
+	}
+	if !strings.Contains(string(recordedArgs), "-l eng") {
+		t.Fatalf("tesseract args = %q, want them to contain %q", recordedArgs, "-l eng")
+	}
+}
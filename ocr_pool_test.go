@@ -0,0 +1,290 @@
+package haseb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+
+	"github.com/awabrh/haseb/preproc"
+)
+
+// fakeEngine is a trivial Engine for exercising OCRPool without a real
+// Tesseract install.
+type fakeEngine struct {
+	mutex     sync.Mutex
+	closed    bool
+	langs     []string
+	lastImage []byte
+
+	// setLanguageHook, if set, runs before SetLanguage records langs and
+	// can force it to fail, e.g. to simulate a worker's post-job language
+	// restore failing.
+	setLanguageHook func(langs []string) error
+}
+
+func (f *fakeEngine) SetImage(imagePath string) error { return nil }
+
+func (f *fakeEngine) SetImageFromBytes(data []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.lastImage = data
+	return nil
+}
+
+func (f *fakeEngine) lastImageData() []byte {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.lastImage
+}
+
+func (f *fakeEngine) SetLanguage(langs ...string) error {
+	f.mutex.Lock()
+	hook := f.setLanguageHook
+	f.mutex.Unlock()
+	if hook != nil {
+		if err := hook(langs); err != nil {
+			return err
+		}
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.langs = langs
+	return nil
+}
+
+func (f *fakeEngine) Text() (string, error)     { return "fake text", nil }
+func (f *fakeEngine) HOCRText() (string, error) { return "<fake-hocr/>", nil }
+
+func (f *fakeEngine) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.closed = true
+	return nil
+}
+
+// TestRunOCRJobSurfacesLanguageRestoreFailure pins the bug where a worker
+// whose post-job language restore (job.Langs -> defaultLangs) fails kept
+// silently misrouting every later job to the overridden language, with
+// nothing in OCRResult to show why.
+func TestRunOCRJobSurfacesLanguageRestoreFailure(t *testing.T) {
+	restoreErr := errors.New("restore boom")
+	engine := &fakeEngine{
+		setLanguageHook: func(langs []string) error {
+			if len(langs) == 1 && langs[0] == "eng" {
+				return restoreErr
+			}
+			return nil
+		},
+	}
+
+	result := runOCRJob(engine, OCRJob{ImageData: encodeTestPNG(t), Langs: []string{"fra"}}, []string{"eng"}, nil)
+
+	if result.Text != "fake text" {
+		t.Fatalf("Text = %q, want %q (job itself should still have succeeded)", result.Text, "fake text")
+	}
+	if !strings.Contains(result.Error, restoreErr.Error()) {
+		t.Fatalf("Error = %q, want it to mention %q", result.Error, restoreErr.Error())
+	}
+}
+
+func newFakePool(t *testing.T, size int) *OCRPool {
+	t.Helper()
+	pool, err := NewOCRPoolWithFactory(size, func() (Engine, error) {
+		return &fakeEngine{}, nil
+	}, "eng")
+	if err != nil {
+		t.Fatalf("NewOCRPoolWithFactory: %v", err)
+	}
+	return pool
+}
+
+// encodeTestPNG produces a valid 2x2 PNG, since a pipeline stage runs
+// through image.Decode before it ever sees the raw bytes.
+func encodeTestPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestPreprocessImageDecodesEveryIsImageFileFormat pins that every format
+// isImageFile advertises (.jpg/.jpeg/.png/.bmp/.tiff) is actually
+// decodable by preprocessImage's image.Decode call — golang.org/x/image's
+// bmp and tiff packages are blank-imported in ocr.go alongside the
+// standard library's jpeg/png for exactly this.
+func TestPreprocessImageDecodesEveryIsImageFileFormat(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+
+	encoders := map[string]func(io.Writer, image.Image) error{
+		"png":  png.Encode,
+		"jpeg": func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) },
+		"bmp":  bmp.Encode,
+		"tiff": func(w io.Writer, img image.Image) error { return tiff.Encode(w, img, nil) },
+	}
+
+	for format, encode := range encoders {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := encode(&buf, img); err != nil {
+				t.Fatalf("encode %s: %v", format, err)
+			}
+
+			out, err := preprocessImage(preproc.Pipeline{preproc.Grayscale}, buf.Bytes())
+			if err != nil {
+				t.Fatalf("preprocessImage: %v", err)
+			}
+			if len(out) == 0 {
+				t.Fatal("preprocessImage returned no bytes")
+			}
+		})
+	}
+}
+
+// TestOCRPoolRunsPreprocessors pins the bug fixed in 470797f: a job
+// submitted to a pool configured with WithPreprocessors must have its
+// image run through the pipeline before the engine ever sees it, not the
+// raw bytes.
+func TestOCRPoolRunsPreprocessors(t *testing.T) {
+	var stageRan bool
+	stage := preproc.Stage(func(img image.Image) (image.Image, error) {
+		stageRan = true
+		out := image.NewGray(img.Bounds())
+		for y := out.Bounds().Min.Y; y < out.Bounds().Max.Y; y++ {
+			for x := out.Bounds().Min.X; x < out.Bounds().Max.X; x++ {
+				out.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+		return out, nil
+	})
+
+	engine := &fakeEngine{}
+	pool, err := NewOCRPoolWithFactory(1, func() (Engine, error) {
+		return engine, nil
+	}, "eng")
+	if err != nil {
+		t.Fatalf("NewOCRPoolWithFactory: %v", err)
+	}
+	pool.WithPreprocessors(stage)
+
+	raw := encodeTestPNG(t)
+	resultCh, err := pool.Submit(context.Background(), OCRJob{ImageData: raw})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	select {
+	case result := <-resultCh:
+		if result.Error != "" {
+			t.Fatalf("unexpected job error: %s", result.Error)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+
+	if !stageRan {
+		t.Fatal("pipeline stage was never invoked")
+	}
+
+	got := engine.lastImageData()
+	if bytes.Equal(got, raw) {
+		t.Fatal("engine received the raw image bytes; preprocessing pipeline was bypassed")
+	}
+	if len(got) == 0 {
+		t.Fatal("engine received no image data")
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestOCRPoolSubmitAndShutdown(t *testing.T) {
+	pool := newFakePool(t, 2)
+
+	resultCh, err := pool.Submit(context.Background(), OCRJob{ImageData: []byte("data")})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	select {
+	case result := <-resultCh:
+		if result.Error != "" {
+			t.Fatalf("unexpected job error: %s", result.Error)
+		}
+		if result.Text != "fake text" {
+			t.Fatalf("Text = %q, want %q", result.Text, "fake text")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for result")
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func TestOCRPoolSubmitAfterShutdownFails(t *testing.T) {
+	pool := newFakePool(t, 1)
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	if _, err := pool.Submit(context.Background(), OCRJob{ImageData: []byte("data")}); err == nil {
+		t.Fatal("expected Submit to fail after Shutdown, got nil error")
+	}
+}
+
+// TestOCRPoolConcurrentSubmitDuringShutdown exercises the race Submit and
+// Shutdown guard against: many goroutines calling Submit concurrently with
+// a Shutdown, none of which should ever panic with "send on closed
+// channel" — Shutdown must wait for every admitted Submit to finish
+// sending before it closes the jobs channel.
+func TestOCRPoolConcurrentSubmitDuringShutdown(t *testing.T) {
+	const submitters = 50
+
+	pool := newFakePool(t, 4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < submitters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resultCh, err := pool.Submit(context.Background(), OCRJob{ImageData: []byte("data")})
+			if err != nil {
+				return
+			}
+			<-resultCh
+		}()
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	wg.Wait()
+}
+
+func TestOCRPoolShutdownIsIdempotent(t *testing.T) {
+	pool := newFakePool(t, 1)
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("first Shutdown: %v", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second Shutdown: %v", err)
+	}
+}
@@ -0,0 +1,451 @@
+// Package preproc implements composable image-preparation stages that
+// improve Tesseract accuracy on scanned or photographed pages: grayscale
+// conversion, binarization, deskewing, denoising, and DPI normalization.
+package preproc
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Stage transforms an image, returning the result to feed into the next
+// stage (or into Tesseract).
+type Stage func(image.Image) (image.Image, error)
+
+// Pipeline is an ordered sequence of Stages.
+type Pipeline []Stage
+
+// Run applies each stage in order, threading the output of one into the
+// input of the next.
+func (p Pipeline) Run(img image.Image) (image.Image, error) {
+	var err error
+	for _, stage := range p {
+		img, err = stage(img)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// Grayscale converts img to 8-bit grayscale.
+func Grayscale(img image.Image) (image.Image, error) {
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x, y, img.At(x, y))
+		}
+	}
+	return gray, nil
+}
+
+// BinarizeOtsu converts img to grayscale and thresholds it using Otsu's
+// method, which picks the single global threshold that minimizes
+// within-class pixel-intensity variance.
+func BinarizeOtsu(img image.Image) (image.Image, error) {
+	gray := toGray(img)
+	threshold := otsuThreshold(gray)
+
+	bounds := gray.Bounds()
+	out := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			out.SetGray(x, y, binarize(gray.GrayAt(x, y).Y, threshold))
+		}
+	}
+	return out, nil
+}
+
+// otsuThreshold computes Otsu's global threshold from gray's intensity
+// histogram.
+func otsuThreshold(gray *image.Gray) uint8 {
+	var histogram [256]int
+	for _, v := range gray.Pix {
+		histogram[v]++
+	}
+
+	total := len(gray.Pix)
+	var sum float64
+	for i, count := range histogram {
+		sum += float64(i * count)
+	}
+
+	var sumB, weightB float64
+	var maxVariance float64
+	var threshold uint8
+
+	for t := 0; t < 256; t++ {
+		weightB += float64(histogram[t])
+		if weightB == 0 {
+			continue
+		}
+		weightF := float64(total) - weightB
+		if weightF == 0 {
+			break
+		}
+
+		sumB += float64(t * histogram[t])
+		meanB := sumB / weightB
+		meanF := (sum - sumB) / weightF
+
+		betweenVariance := weightB * weightF * (meanB - meanF) * (meanB - meanF)
+		if betweenVariance > maxVariance {
+			maxVariance = betweenVariance
+			threshold = uint8(t)
+		}
+	}
+	return threshold
+}
+
+// SauvolaOptions configures BinarizeSauvola.
+type SauvolaOptions struct {
+	// Window is the side length of the square local neighborhood used to
+	// compute each pixel's threshold. Must be odd and >= 3.
+	Window int
+	// K controls how aggressively the local threshold is pulled below the
+	// local mean in low-contrast regions. Typical value: 0.34.
+	K float64
+	// R is the dynamic range of the local standard deviation. Typical
+	// value: 128 (the default for 8-bit grayscale).
+	R float64
+}
+
+// DefaultSauvolaOptions are the values recommended by Sauvola & Pietikäinen
+// for scanned text.
+func DefaultSauvolaOptions() SauvolaOptions {
+	return SauvolaOptions{Window: 31, K: 0.34, R: 128}
+}
+
+// BinarizeSauvola converts img to grayscale and thresholds it using
+// Sauvola's adaptive method, which computes a local threshold
+// T(x,y) = m(x,y) * (1 + k*(s(x,y)/R - 1)) from the local mean m and
+// standard deviation s over a window around each pixel. This copes far
+// better than a single global threshold with uneven scan lighting.
+func BinarizeSauvola(opts SauvolaOptions) Stage {
+	return func(img image.Image) (image.Image, error) {
+		gray := toGray(img)
+		bounds := gray.Bounds()
+		w, h := bounds.Dx(), bounds.Dy()
+
+		sum, sumSq := integralImages(gray)
+		radius := opts.Window / 2
+
+		out := image.NewGray(bounds)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				mean, stddev := windowStats(sum, sumSq, w, h, x, y, radius)
+				threshold := mean * (1 + opts.K*(stddev/opts.R-1))
+				out.SetGray(bounds.Min.X+x, bounds.Min.Y+y, binarize(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y, uint8(clamp(threshold, 0, 255))))
+			}
+		}
+		return out, nil
+	}
+}
+
+// integralImages builds summed-area tables for gray's pixel values and
+// their squares, enabling O(1) window mean/stddev lookups.
+func integralImages(gray *image.Gray) (sum, sumSq []float64) {
+	bounds := gray.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	sum = make([]float64, (w+1)*(h+1))
+	sumSq = make([]float64, (w+1)*(h+1))
+	stride := w + 1
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := float64(gray.GrayAt(bounds.Min.X+x, bounds.Min.Y+y).Y)
+			idx := (y+1)*stride + (x + 1)
+			sum[idx] = v + sum[idx-1] + sum[idx-stride] - sum[idx-stride-1]
+			sumSq[idx] = v*v + sumSq[idx-1] + sumSq[idx-stride] - sumSq[idx-stride-1]
+		}
+	}
+	return sum, sumSq
+}
+
+// windowStats returns the mean and standard deviation of the square
+// window of the given radius centered on (x, y), using the integral
+// images produced by integralImages.
+func windowStats(sum, sumSq []float64, w, h, x, y, radius int) (mean, stddev float64) {
+	stride := w + 1
+
+	x0 := maxInt(0, x-radius)
+	y0 := maxInt(0, y-radius)
+	x1 := minInt(w-1, x+radius)
+	y1 := minInt(h-1, y+radius)
+
+	area := float64((x1 - x0 + 1) * (y1 - y0 + 1))
+
+	regionSum := windowLookup(sum, stride, x0, y0, x1, y1)
+	regionSumSq := windowLookup(sumSq, stride, x0, y0, x1, y1)
+
+	mean = regionSum / area
+	variance := regionSumSq/area - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return mean, math.Sqrt(variance)
+}
+
+// windowLookup reads the sum over [x0,x1]x[y0,y1] from a summed-area
+// table with the given stride (width+1).
+func windowLookup(table []float64, stride, x0, y0, x1, y1 int) float64 {
+	a := table[y0*stride+x0]
+	b := table[y0*stride+(x1+1)]
+	c := table[(y1+1)*stride+x0]
+	d := table[(y1+1)*stride+(x1+1)]
+	return d - b - c + a
+}
+
+// Denoise applies a radius-1 median filter, which removes scanner speckle
+// and JPEG artifacts while preserving text edges better than a blur.
+func Denoise(img image.Image) (image.Image, error) {
+	gray := toGray(img)
+	bounds := gray.Bounds()
+	out := image.NewGray(bounds)
+
+	var window [9]uint8
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					px := clampInt(x+dx, bounds.Min.X, bounds.Max.X-1)
+					py := clampInt(y+dy, bounds.Min.Y, bounds.Max.Y-1)
+					window[n] = gray.GrayAt(px, py).Y
+					n++
+				}
+			}
+			out.SetGray(x, y, color.Gray{Y: median9(window)})
+		}
+	}
+	return out, nil
+}
+
+// median9 returns the median of a 9-element window via insertion sort,
+// which is cheaper than a general sort for such a small fixed size.
+func median9(window [9]uint8) uint8 {
+	for i := 1; i < len(window); i++ {
+		v := window[i]
+		j := i - 1
+		for j >= 0 && window[j] > v {
+			window[j+1] = window[j]
+			j--
+		}
+		window[j+1] = v
+	}
+	return window[4]
+}
+
+// TargetDPI is the resolution Tesseract is documented to perform best at.
+const TargetDPI = 300
+
+// UpscaleToDPI resizes img by nearest-neighbor so that an image scanned at
+// sourceDPI is normalized to somewhere in Tesseract's preferred 300-400
+// DPI range. Images already at or above 300 DPI are left unchanged.
+func UpscaleToDPI(sourceDPI int) Stage {
+	return func(img image.Image) (image.Image, error) {
+		if sourceDPI <= 0 || sourceDPI >= TargetDPI {
+			return img, nil
+		}
+
+		scale := float64(TargetDPI) / float64(sourceDPI)
+		bounds := img.Bounds()
+		newW := int(float64(bounds.Dx()) * scale)
+		newH := int(float64(bounds.Dy()) * scale)
+
+		out := image.NewRGBA(image.Rect(0, 0, newW, newH))
+		for y := 0; y < newH; y++ {
+			for x := 0; x < newW; x++ {
+				srcX := bounds.Min.X + int(float64(x)/scale)
+				srcY := bounds.Min.Y + int(float64(y)/scale)
+				out.Set(x, y, img.At(srcX, srcY))
+			}
+		}
+		return out, nil
+	}
+}
+
+// DeskewOptions configures Deskew.
+type DeskewOptions struct {
+	// MaxAngle bounds the range of rotation angles searched, in degrees.
+	MaxAngle float64
+	// AngleStep is the resolution of the angle search, in degrees.
+	AngleStep float64
+}
+
+// DefaultDeskewOptions search +/-15 degrees, which covers the vast
+// majority of scanner and photograph skew.
+func DefaultDeskewOptions() DeskewOptions {
+	return DeskewOptions{MaxAngle: 15, AngleStep: 0.25}
+}
+
+// Deskew estimates the page rotation via a Hough-line angle vote over
+// Sobel edge pixels and rotates the image to correct it.
+func Deskew(opts DeskewOptions) Stage {
+	return func(img image.Image) (image.Image, error) {
+		gray := toGray(img)
+		angle := estimateSkewAngle(gray, opts)
+		if angle == 0 {
+			return img, nil
+		}
+		return rotate(img, angle), nil
+	}
+}
+
+// estimateSkewAngle finds the rotation angle whose Hough accumulator bin
+// (over edge pixels detected by a simple Sobel gradient) receives the
+// most votes.
+func estimateSkewAngle(gray *image.Gray, opts DeskewOptions) float64 {
+	edges := sobelEdges(gray)
+	if len(edges) == 0 {
+		return 0
+	}
+
+	bestAngle := 0.0
+	bestVotes := -1
+
+	for angle := -opts.MaxAngle; angle <= opts.MaxAngle; angle += opts.AngleStep {
+		rad := angle * math.Pi / 180
+		sin, cos := math.Sin(rad), math.Cos(rad)
+
+		votes := make(map[int]int)
+		for _, p := range edges {
+			// Project each edge point onto the normal of a line at this
+			// angle; points on the same text line land in the same bin.
+			rho := int(float64(p.X)*sin + float64(p.Y)*cos)
+			votes[rho]++
+		}
+
+		peak := 0
+		for _, v := range votes {
+			if v > peak {
+				peak = v
+			}
+		}
+		if peak > bestVotes {
+			bestVotes = peak
+			bestAngle = angle
+		}
+	}
+	return bestAngle
+}
+
+// sobelEdges returns the coordinates of pixels whose Sobel gradient
+// magnitude exceeds a fixed threshold.
+func sobelEdges(gray *image.Gray) []image.Point {
+	const threshold = 128
+	bounds := gray.Bounds()
+
+	var points []image.Point
+	for y := bounds.Min.Y + 1; y < bounds.Max.Y-1; y++ {
+		for x := bounds.Min.X + 1; x < bounds.Max.X-1; x++ {
+			gx := sobelAt(gray, x, y, true)
+			gy := sobelAt(gray, x, y, false)
+			magnitude := math.Sqrt(float64(gx*gx + gy*gy))
+			if magnitude > threshold {
+				points = append(points, image.Pt(x, y))
+			}
+		}
+	}
+	return points
+}
+
+// sobelAt computes the horizontal (horizontal=true) or vertical Sobel
+// gradient at (x, y).
+func sobelAt(gray *image.Gray, x, y int, horizontal bool) int {
+	px := func(dx, dy int) int { return int(gray.GrayAt(x+dx, y+dy).Y) }
+
+	if horizontal {
+		return (px(1, -1) + 2*px(1, 0) + px(1, 1)) - (px(-1, -1) + 2*px(-1, 0) + px(-1, 1))
+	}
+	return (px(-1, 1) + 2*px(0, 1) + px(1, 1)) - (px(-1, -1) + 2*px(0, -1) + px(1, -1))
+}
+
+// rotate rotates img by angleDegrees (counter-clockwise positive) around
+// its center, using nearest-neighbor sampling.
+func rotate(img image.Image, angleDegrees float64) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	cx, cy := float64(w)/2, float64(h)/2
+
+	rad := angleDegrees * math.Pi / 180
+	sin, cos := math.Sin(rad), math.Cos(rad)
+
+	out := image.NewRGBA(bounds)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			dx, dy := float64(x)-cx, float64(y)-cy
+			srcX := int(dx*cos+dy*sin+cx) + bounds.Min.X
+			srcY := int(-dx*sin+dy*cos+cy) + bounds.Min.Y
+			if (image.Point{X: srcX, Y: srcY}).In(bounds) {
+				out.Set(x, y, img.At(srcX, srcY))
+			} else {
+				out.Set(x, y, color.White)
+			}
+		}
+	}
+	return out
+}
+
+// toGray returns img as *image.Gray, converting it if necessary.
+func toGray(img image.Image) *image.Gray {
+	if gray, ok := img.(*image.Gray); ok {
+		return gray
+	}
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			gray.Set(x, y, img.At(x, y))
+		}
+	}
+	return gray
+}
+
+// binarize maps v to black or white depending on threshold. v at or below
+// threshold is black; this matters at the boundary itself, e.g. Otsu's
+// threshold lands exactly on the darker of two clusters in a flat
+// two-tone image, and that cluster still needs to end up black.
+func binarize(v, threshold uint8) color.Gray {
+	if v <= threshold {
+		return color.Gray{Y: 0}
+	}
+	return color.Gray{Y: 255}
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
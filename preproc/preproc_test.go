@@ -0,0 +1,224 @@
+package preproc
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// halfToneImage builds a w x h grayscale image whose left half is dark and
+// right half is bright, for exercising edge detection against a known
+// vertical boundary.
+func halfToneImage(w, h int, dark, bright uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.SetGray(x, y, color.Gray{Y: dark})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: bright})
+			}
+		}
+	}
+	return img
+}
+
+// bandedImage builds a w x h grayscale image of bright background with a
+// horizontal dark band, simulating a line of text against a page — unlike
+// a flat two-tone image, this gives Sauvola's local-contrast windows
+// something to react to even far from the image's own edges.
+func bandedImage(w, h int, bandTop, bandBottom int, background, band uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := background
+			if y >= bandTop && y < bandBottom {
+				v = band
+			}
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+	return img
+}
+
+func TestBinarizeSauvolaProducesBinaryOutput(t *testing.T) {
+	src := halfToneImage(64, 64, 20, 235)
+
+	stage := BinarizeSauvola(DefaultSauvolaOptions())
+	out, err := stage(src)
+	if err != nil {
+		t.Fatalf("BinarizeSauvola: %v", err)
+	}
+
+	gray, ok := out.(*image.Gray)
+	if !ok {
+		t.Fatalf("expected *image.Gray output, got %T", out)
+	}
+	if gray.Bounds() != src.Bounds() {
+		t.Fatalf("output bounds %v != input bounds %v", gray.Bounds(), src.Bounds())
+	}
+	for _, v := range gray.Pix {
+		if v != 0 && v != 255 {
+			t.Fatalf("non-binary pixel value %d", v)
+		}
+	}
+}
+
+func TestBinarizeSauvolaSeparatesTextFromBackground(t *testing.T) {
+	src := bandedImage(64, 64, 28, 36, 235, 20)
+
+	stage := BinarizeSauvola(DefaultSauvolaOptions())
+	out, err := stage(src)
+	if err != nil {
+		t.Fatalf("BinarizeSauvola: %v", err)
+	}
+	gray := out.(*image.Gray)
+
+	if v := gray.GrayAt(32, 32).Y; v != 0 {
+		t.Errorf("dark band pixel = %d, want 0 (text)", v)
+	}
+	if v := gray.GrayAt(32, 5).Y; v != 255 {
+		t.Errorf("background pixel = %d, want 255", v)
+	}
+}
+
+func TestGrayscaleConvertsToGray(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 10, B: 10, A: 255})
+		}
+	}
+
+	out, err := Grayscale(src)
+	if err != nil {
+		t.Fatalf("Grayscale: %v", err)
+	}
+
+	gray, ok := out.(*image.Gray)
+	if !ok {
+		t.Fatalf("expected *image.Gray output, got %T", out)
+	}
+	if gray.Bounds() != src.Bounds() {
+		t.Fatalf("output bounds %v != input bounds %v", gray.Bounds(), src.Bounds())
+	}
+	want := color.GrayModel.Convert(color.RGBA{R: 200, G: 10, B: 10, A: 255}).(color.Gray).Y
+	if v := gray.GrayAt(0, 0).Y; v != want {
+		t.Errorf("GrayAt(0,0) = %d, want %d", v, want)
+	}
+}
+
+func TestBinarizeOtsuSplitsTwoToneImage(t *testing.T) {
+	src := halfToneImage(64, 64, 20, 235)
+
+	out, err := BinarizeOtsu(src)
+	if err != nil {
+		t.Fatalf("BinarizeOtsu: %v", err)
+	}
+	gray, ok := out.(*image.Gray)
+	if !ok {
+		t.Fatalf("expected *image.Gray output, got %T", out)
+	}
+
+	if v := gray.GrayAt(8, 32).Y; v != 0 {
+		t.Errorf("dark-half pixel = %d, want 0", v)
+	}
+	if v := gray.GrayAt(56, 32).Y; v != 255 {
+		t.Errorf("bright-half pixel = %d, want 255", v)
+	}
+	for _, v := range gray.Pix {
+		if v != 0 && v != 255 {
+			t.Fatalf("non-binary pixel value %d", v)
+		}
+	}
+}
+
+func TestDenoiseRemovesIsolatedSpeckle(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+	src.SetGray(4, 4, color.Gray{Y: 0})
+
+	out, err := Denoise(src)
+	if err != nil {
+		t.Fatalf("Denoise: %v", err)
+	}
+	gray := out.(*image.Gray)
+
+	if v := gray.GrayAt(4, 4).Y; v != 255 {
+		t.Errorf("speckle pixel after Denoise = %d, want 255 (removed)", v)
+	}
+	if v := gray.GrayAt(0, 0).Y; v != 255 {
+		t.Errorf("unrelated pixel after Denoise = %d, want unchanged 255", v)
+	}
+}
+
+func TestUpscaleToDPIScalesDimensions(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 10, 20))
+
+	stage := UpscaleToDPI(150)
+	out, err := stage(src)
+	if err != nil {
+		t.Fatalf("UpscaleToDPI: %v", err)
+	}
+
+	wantW, wantH := 20, 40
+	if b := out.Bounds(); b.Dx() != wantW || b.Dy() != wantH {
+		t.Fatalf("output size = %dx%d, want %dx%d", b.Dx(), b.Dy(), wantW, wantH)
+	}
+}
+
+func TestUpscaleToDPINoOpAboveTarget(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 10, 20))
+
+	stage := UpscaleToDPI(TargetDPI)
+	out, err := stage(src)
+	if err != nil {
+		t.Fatalf("UpscaleToDPI: %v", err)
+	}
+	if out != image.Image(src) {
+		t.Fatalf("UpscaleToDPI(TargetDPI) should return the input unchanged")
+	}
+
+	stage = UpscaleToDPI(TargetDPI + 50)
+	out, err = stage(src)
+	if err != nil {
+		t.Fatalf("UpscaleToDPI: %v", err)
+	}
+	if out != image.Image(src) {
+		t.Fatalf("UpscaleToDPI above TargetDPI should return the input unchanged")
+	}
+}
+
+func TestDeskewNoOpOnBlankImage(t *testing.T) {
+	src := image.NewGray(image.Rect(0, 0, 32, 32))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+	}
+
+	stage := Deskew(DefaultDeskewOptions())
+	out, err := stage(src)
+	if err != nil {
+		t.Fatalf("Deskew: %v", err)
+	}
+	if out != image.Image(src) {
+		t.Fatalf("Deskew should return the input unchanged when no edges are found")
+	}
+}
+
+func TestEstimateSkewAngleOnUnskewedTextLine(t *testing.T) {
+	// A horizontal band boundary puts every edge point at the same y, so
+	// the Hough vote for angle 0 (where rho reduces to y) concentrates
+	// votes into far fewer bins than any noticeably non-zero angle does —
+	// exactly the signal a real horizontal text line produces. rho's int()
+	// truncation ties the vote count over a small band of angles around 0,
+	// so this only asserts the winning angle falls within that band rather
+	// than landing on exactly 0.
+	gray := bandedImage(64, 64, 28, 36, 235, 20)
+
+	angle := estimateSkewAngle(gray, DefaultDeskewOptions())
+	if angle < -2 || angle > 2 {
+		t.Errorf("estimateSkewAngle on an unskewed text line = %v, want within +/-2 degrees", angle)
+	}
+}
@@ -0,0 +1,278 @@
+// Package server exposes the OCR functionality in this module over HTTP,
+// turning it from a library into a deployable service.
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	haseb "github.com/awabrh/haseb"
+)
+
+// maxUploadBytes caps the size of any request body accepted by /ocr.
+const maxUploadBytes = 20 << 20 // 20MB
+
+// fetchTimeout bounds how long downloadImage waits for an img_url fetch.
+const fetchTimeout = 10 * time.Second
+
+// maxRedirects bounds how many redirects downloadImage will follow.
+const maxRedirects = 5
+
+// imageFetchClient is used for img_url downloads. Its Transport dials by
+// IP after validating it (rather than trusting a separate, earlier
+// resolution), which closes the DNS-rebinding TOCTOU window a
+// resolve-then-connect check would leave open, and its CheckRedirect
+// re-validates every hop so a redirect can't be used to reach a
+// disallowed address.
+var imageFetchClient = &http.Client{
+	Timeout: fetchTimeout,
+	Transport: &http.Transport{
+		DialContext: dialPublicOnly,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("too many redirects")
+		}
+		if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+			return fmt.Errorf("disallowed redirect scheme: %s", req.URL.Scheme)
+		}
+		return nil
+	},
+}
+
+// dialPublicOnly resolves addr's host and connects to the first resolved
+// IP that isn't loopback, link-local, private, or otherwise internal-use,
+// rejecting the dial entirely if none qualify. This is what actually
+// enforces the destination restriction: http.Transport calls this to
+// make the real connection, so whatever IP is checked here is the IP
+// that's used.
+func dialPublicOnly(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %v", addr, err)
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve host %q: %v", host, err)
+	}
+
+	dialer := &net.Dialer{Timeout: fetchTimeout}
+	var lastErr error
+	for _, ip := range ips {
+		if !isPubliclyRoutable(ip) {
+			lastErr = fmt.Errorf("refusing to fetch from disallowed address %s", ip)
+			continue
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no addresses found for host %q", host)
+	}
+	return nil, lastErr
+}
+
+// carrierGradeNAT is 100.64.0.0/10, the shared address space RFC 6598
+// reserves for carrier-grade NAT. Several cloud providers route internal
+// traffic (including metadata-adjacent services) over it, and net.IP's
+// IsPrivate doesn't cover it, so it needs its own check.
+var carrierGradeNAT = &net.IPNet{
+	IP:   net.IPv4(100, 64, 0, 0),
+	Mask: net.CIDRMask(10, 32),
+}
+
+// isPubliclyRoutable reports whether ip is safe for this server to
+// connect to on a caller's behalf, excluding loopback, link-local
+// (including the 169.254.169.254 cloud metadata address), private,
+// carrier-grade NAT, and unspecified ranges.
+func isPubliclyRoutable(ip net.IP) bool {
+	switch {
+	case ip.IsLoopback(),
+		ip.IsLinkLocalUnicast(),
+		ip.IsLinkLocalMulticast(),
+		ip.IsPrivate(),
+		ip.IsUnspecified(),
+		ip.IsMulticast(),
+		carrierGradeNAT.Contains(ip):
+		return false
+	default:
+		return true
+	}
+}
+
+// Server dispatches OCR requests across an OCRPool so concurrent requests
+// don't serialize on a single Tesseract engine.
+type Server struct {
+	pool *haseb.OCRPool
+	mux  *http.ServeMux
+}
+
+// New creates a Server backed by pool and registers its routes.
+func New(pool *haseb.OCRPool) *Server {
+	s := &Server{
+		pool: pool,
+		mux:  http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/ocr", s.handleOCR)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// jsonUploadRequest is the body accepted for base64/URL uploads.
+type jsonUploadRequest struct {
+	ImageBase64 string `json:"img_base64"`
+	ImageURL    string `json:"img_url"`
+}
+
+// handleOCR accepts a multipart file upload, a JSON body with
+// "img_base64", or a JSON body with "img_url", runs OCR, and returns the
+// resulting OCRResult as JSON.
+func (s *Server) handleOCR(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	var langs []string
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		langs = strings.Split(lang, "+")
+	}
+
+	imageData, err := readImageData(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resultCh, err := s.pool.Submit(r.Context(), haseb.OCRJob{ImageData: imageData, Langs: langs})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	select {
+	case result := <-resultCh:
+		if result.Error != "" {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("%s", result.Error))
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	case <-r.Context().Done():
+		writeError(w, http.StatusRequestTimeout, r.Context().Err())
+	}
+}
+
+// readImageData extracts image bytes from a multipart upload, a
+// base64-encoded JSON body, or a JSON body naming a URL to download.
+func readImageData(r *http.Request) ([]byte, error) {
+	contentType := r.Header.Get("Content-Type")
+
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("missing multipart field \"file\": %v", err)
+		}
+		defer file.Close()
+		return io.ReadAll(file)
+	}
+
+	var body jsonUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid request body: %v", err)
+	}
+
+	switch {
+	case body.ImageBase64 != "":
+		return decodeBase64Image(body.ImageBase64)
+	case body.ImageURL != "":
+		return downloadImage(r.Context(), body.ImageURL)
+	default:
+		return nil, fmt.Errorf("request must provide a file upload, img_base64, or img_url")
+	}
+}
+
+// decodeBase64Image decodes a base64 image, tolerating a data: URL prefix.
+func decodeBase64Image(encoded string) ([]byte, error) {
+	if idx := strings.Index(encoded, ","); idx != -1 && strings.HasPrefix(encoded, "data:") {
+		encoded = encoded[idx+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid img_base64: %v", err)
+	}
+	return data, nil
+}
+
+// downloadImage fetches imgURL and returns its body, capped at
+// maxUploadBytes. The fetch is bounded by fetchTimeout and restricted to
+// publicly routable destinations by imageFetchClient, so a caller can't
+// use this endpoint to probe or pull data from internal services.
+func downloadImage(ctx context.Context, imgURL string) ([]byte, error) {
+	parsed, err := url.ParseRequestURI(imgURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("invalid img_url: %q", imgURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imgURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid img_url: %v", err)
+	}
+
+	resp, err := imageFetchClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch img_url: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch img_url: status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxUploadBytes))
+}
+
+// handleHealthz reports whether tesseract is installed and reachable.
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if err := haseb.CheckTesseractInstallation(); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// ListenAndServe starts the HTTP server on addr using pool.
+func ListenAndServe(addr string, pool *haseb.OCRPool) error {
+	return http.ListenAndServe(addr, New(pool))
+}
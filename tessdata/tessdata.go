@@ -0,0 +1,246 @@
+// Package tessdata resolves Tesseract language codes to .traineddata
+// files, downloading and caching them on demand so multilingual
+// deployments don't depend on what happens to be installed on the host.
+package tessdata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Variant selects which tessdata release to pull traineddata from.
+type Variant int
+
+const (
+	// Fast trades some accuracy for smaller, faster models (tessdata_fast).
+	Fast Variant = iota
+	// Best maximizes accuracy with larger LSTM models (tessdata_best).
+	Best
+	// Standard is the original tessdata models.
+	Standard
+)
+
+// repo returns the GitHub repository this variant's traineddata files are
+// published under.
+func (v Variant) repo() string {
+	switch v {
+	case Best:
+		return "tessdata_best"
+	case Standard:
+		return "tessdata"
+	default:
+		return "tessdata_fast"
+	}
+}
+
+// releaseURL returns the download URL for lang's traineddata file under
+// this variant.
+func (v Variant) releaseURL(lang string) string {
+	return fmt.Sprintf("https://github.com/tesseract-ocr/%s/raw/main/%s.traineddata", v.repo(), lang)
+}
+
+// Manager resolves language codes to cached .traineddata files,
+// downloading them on first use.
+type Manager struct {
+	cacheDir  string
+	variant   Variant
+	client    *http.Client
+	checksums map[string]string
+
+	// unverified accumulates languages whose most recent download had no
+	// WithChecksums entry and no prior pin to verify against (see download).
+	unverified []string
+}
+
+// Option configures a new Manager.
+type Option func(*Manager)
+
+// WithVariant selects which tessdata release to download from. Defaults
+// to Fast.
+func WithVariant(v Variant) Option {
+	return func(m *Manager) {
+		m.variant = v
+	}
+}
+
+// WithCacheDir overrides the default cache directory.
+func WithCacheDir(dir string) Option {
+	return func(m *Manager) {
+		m.cacheDir = dir
+	}
+}
+
+// WithChecksums supplies expected SHA-256 hex digests, keyed by language
+// code, to verify downloads against. The upstream tessdata releases don't
+// publish official per-file checksums, so languages with no entry here are
+// only trust-on-first-use verified (see download) rather than checked
+// against a known-good hash; check UnverifiedLanguages after
+// EnsureLanguages to see which languages that applied to.
+func WithChecksums(checksums map[string]string) Option {
+	return func(m *Manager) {
+		m.checksums = checksums
+	}
+}
+
+// WithHTTPClient overrides the client used to download traineddata files.
+func WithHTTPClient(client *http.Client) Option {
+	return func(m *Manager) {
+		m.client = client
+	}
+}
+
+// NewManager creates a Manager, defaulting its cache directory to
+// $XDG_CACHE_HOME/haseb/tessdata (or ~/.cache/haseb/tessdata if
+// XDG_CACHE_HOME is unset) and creating it if necessary.
+func NewManager(opts ...Option) (*Manager, error) {
+	m := &Manager{
+		cacheDir: defaultCacheDir(),
+		variant:  Fast,
+		client:   http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if err := os.MkdirAll(m.cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create tessdata cache dir: %v", err)
+	}
+	return m, nil
+}
+
+// defaultCacheDir computes $XDG_CACHE_HOME/haseb/tessdata, falling back to
+// ~/.cache/haseb/tessdata.
+func defaultCacheDir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "haseb", "tessdata")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "haseb", "tessdata")
+	}
+	return filepath.Join(home, ".cache", "haseb", "tessdata")
+}
+
+// EnsureLanguages downloads any of langs missing from the cache dir and
+// points TESSDATA_PREFIX at it, so a subsequent NewOCRClient/SetLanguage
+// call finds them. Call UnverifiedLanguages afterward to find out which
+// languages, if any, were accepted trust-on-first-use rather than checked
+// against a known-good hash.
+func (m *Manager) EnsureLanguages(ctx context.Context, langs ...string) error {
+	m.unverified = nil
+	for _, lang := range langs {
+		if err := m.ensureLanguage(ctx, lang); err != nil {
+			return fmt.Errorf("failed to ensure language %q: %v", lang, err)
+		}
+	}
+	return os.Setenv("TESSDATA_PREFIX", m.cacheDir)
+}
+
+// UnverifiedLanguages returns the languages, among those most recently
+// passed to EnsureLanguages, that were newly downloaded with no
+// WithChecksums entry and no prior pin to verify against — i.e. trusted on
+// first use rather than verified against a known-good hash. Callers that
+// care about supply-chain integrity should treat a non-empty result as
+// worth surfacing to an operator.
+func (m *Manager) UnverifiedLanguages() []string {
+	return m.unverified
+}
+
+// ensureLanguage downloads lang's traineddata file if it isn't already
+// cached.
+func (m *Manager) ensureLanguage(ctx context.Context, lang string) error {
+	path := m.path(lang)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return m.download(ctx, lang, path)
+}
+
+// path returns the cached file path for lang's traineddata.
+func (m *Manager) path(lang string) string {
+	return filepath.Join(m.cacheDir, lang+".traineddata")
+}
+
+// sidecarPath returns where lang's pinned checksum is stored.
+func (m *Manager) sidecarPath(lang string) string {
+	return m.path(lang) + ".sha256"
+}
+
+// pinnedChecksum returns the checksum pinned for lang on a previous
+// download, if any.
+func (m *Manager) pinnedChecksum(lang string) (string, bool) {
+	data, err := os.ReadFile(m.sidecarPath(lang))
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// download fetches lang's traineddata from this Manager's variant and
+// atomically installs it at destPath. The upstream tessdata releases
+// don't publish per-file checksums, so by default this pins the SHA-256
+// of the first successful download in a sidecar file and verifies any
+// later (re-)download against that pin, rather than installing every
+// download unverified; callers who have an out-of-band known-good hash
+// should pass WithChecksums to verify against it from the first download
+// on.
+func (m *Manager) download(ctx context.Context, lang, destPath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.variant.releaseURL(lang), nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %v", err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download traineddata: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download traineddata: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read traineddata response: %v", err)
+	}
+
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+
+	pinned := false
+	if expected, ok := m.checksums[lang]; ok {
+		if got != expected {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", lang, expected, got)
+		}
+	} else if expected, ok := m.pinnedChecksum(lang); ok {
+		if got != expected {
+			return fmt.Errorf("checksum mismatch for %s: traineddata changed since it was first downloaded (pinned %s, got %s); pass WithChecksums to pin an explicit known-good hash", lang, expected, got)
+		}
+	} else {
+		pinned = true
+		m.unverified = append(m.unverified, lang)
+	}
+
+	tmpPath := destPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write traineddata: %v", err)
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		return fmt.Errorf("failed to install traineddata: %v", err)
+	}
+
+	if pinned {
+		if err := os.WriteFile(m.sidecarPath(lang), []byte(got), 0o644); err != nil {
+			return fmt.Errorf("failed to pin checksum: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package tessdata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+)
+
+// redirectToServer is an http.RoundTripper that sends every request to
+// srv instead of its original host, so a Manager configured with the
+// real (github.com) Variant URLs can be pointed at a test server without
+// changing how releaseURL is built.
+type redirectToServer struct {
+	srv *httptest.Server
+}
+
+func (rt redirectToServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(rt.srv.URL)
+	if err != nil {
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.URL.Scheme = target.Scheme
+	out.URL.Host = target.Host
+	return rt.srv.Client().Transport.RoundTrip(out)
+}
+
+// newTestServer serves a distinct, fixed body per language so each
+// traineddata file gets its own checksum.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("traineddata-for-" + r.URL.Path))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestManager(t *testing.T, srv *httptest.Server) *Manager {
+	t.Helper()
+	m, err := NewManager(
+		WithCacheDir(t.TempDir()),
+		WithHTTPClient(&http.Client{Transport: redirectToServer{srv}}),
+	)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+func TestEnsureLanguagesUnverifiedResetsPerCall(t *testing.T) {
+	srv := newTestServer(t)
+	m := newTestManager(t, srv)
+
+	if err := m.EnsureLanguages(context.Background(), "eng", "fra"); err != nil {
+		t.Fatalf("EnsureLanguages: %v", err)
+	}
+	got := m.UnverifiedLanguages()
+	if len(got) != 2 || got[0] != "eng" || got[1] != "fra" {
+		t.Fatalf("UnverifiedLanguages after first call = %v, want [eng fra]", got)
+	}
+
+	// A second call for an already-cached language plus one newly
+	// downloaded language should report only the languages unverified
+	// in THIS call, not the accumulated history from the first.
+	if err := m.EnsureLanguages(context.Background(), "eng", "deu"); err != nil {
+		t.Fatalf("EnsureLanguages: %v", err)
+	}
+	got = m.UnverifiedLanguages()
+	if len(got) != 1 || got[0] != "deu" {
+		t.Fatalf("UnverifiedLanguages after second call = %v, want [deu]", got)
+	}
+
+	if _, err := os.Stat(m.path("eng")); err != nil {
+		t.Fatalf("expected cached traineddata at %s: %v", m.path("eng"), err)
+	}
+}
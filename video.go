@@ -0,0 +1,161 @@
+package haseb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Region is a named rectangular area of interest within a video frame,
+// letting callers OCR only a small overlay (e.g. a scoreboard or
+// timestamp) instead of the full frame.
+type Region struct {
+	Name string
+	Rect image.Rectangle
+}
+
+// VideoResult pairs an OCRResult with the timestamp of the frame it came
+// from and, if the result is for a named region, that region's name.
+type VideoResult struct {
+	*OCRResult
+	Timestamp time.Duration `json:"video_timestamp"`
+	Region    string        `json:"region,omitempty"`
+}
+
+// VideoOCR extracts frames from a video source via ffmpeg and OCRs each
+// one, emitting a stream of results as they become available.
+type VideoOCR struct {
+	client  *OCRClient
+	fps     float64
+	regions []Region
+}
+
+// NewVideoOCR creates a VideoOCR that samples frames at fps (frames per
+// second of input consumed, not wall-clock) and runs them through client.
+// If regions are given, each frame is cropped to each region and OCR'd
+// separately instead of OCRing the full frame.
+func NewVideoOCR(client *OCRClient, fps float64, regions ...Region) (*VideoOCR, error) {
+	if fps <= 0 {
+		return nil, fmt.Errorf("fps must be positive, got %v", fps)
+	}
+	return &VideoOCR{client: client, fps: fps, regions: regions}, nil
+}
+
+// CheckFFmpegInstallation verifies if ffmpeg is installed, mirroring
+// CheckTesseractInstallation.
+func CheckFFmpegInstallation() error {
+	_, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return fmt.Errorf("ffmpeg is not installed: %v", err)
+	}
+	return nil
+}
+
+// Run starts ffmpeg against source (a file path or a stream URL such as
+// an RTMP/HLS address) and sends one VideoResult per sampled frame (or,
+// with regions configured, one per region per frame) on the returned
+// channel. The channel is closed when ffmpeg's frame stream or ctx ends.
+func (v *VideoOCR) Run(ctx context.Context, source string) (<-chan *VideoResult, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-i", source,
+		"-vf", "fps="+strconv.FormatFloat(v.fps, 'f', -1, 64),
+		"-f", "image2pipe",
+		"-vcodec", "png",
+		"-",
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ffmpeg stdout: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start ffmpeg: %v", err)
+	}
+
+	results := make(chan *VideoResult)
+	go v.consumeFrames(ctx, cmd, stdout, results)
+	return results, nil
+}
+
+// consumeFrames decodes the concatenated PNG frame stream from stdout,
+// OCRs each frame (optionally per-region), and publishes results until
+// the stream or ctx ends.
+func (v *VideoOCR) consumeFrames(ctx context.Context, cmd *exec.Cmd, stdout io.Reader, results chan<- *VideoResult) {
+	defer close(results)
+	defer cmd.Wait()
+
+	reader := bufio.NewReaderSize(stdout, 1<<20)
+	frameIndex := 0
+
+	for {
+		img, err := png.Decode(reader)
+		if err != nil {
+			return
+		}
+
+		timestamp := time.Duration(float64(frameIndex) / v.fps * float64(time.Second))
+		frameIndex++
+
+		if err := v.emitFrame(ctx, img, timestamp, results); err != nil {
+			return
+		}
+	}
+}
+
+// emitFrame OCRs img (as a whole, or per configured region) and sends the
+// resulting VideoResults, returning an error only if ctx is done.
+func (v *VideoOCR) emitFrame(ctx context.Context, img image.Image, timestamp time.Duration, results chan<- *VideoResult) error {
+	if len(v.regions) == 0 {
+		return sendResult(ctx, results, v.ocrImage(img, timestamp, ""))
+	}
+
+	for _, region := range v.regions {
+		cropped, ok := img.(interface {
+			SubImage(r image.Rectangle) image.Image
+		})
+		if !ok {
+			continue
+		}
+		if err := sendResult(ctx, results, v.ocrImage(cropped.SubImage(region.Rect), timestamp, region.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ocrImage re-encodes img as PNG and runs it through the client, wrapping
+// any failure as an OCRResult.Error rather than dropping the frame.
+func (v *VideoOCR) ocrImage(img image.Image, timestamp time.Duration, region string) *VideoResult {
+	var buf bytes.Buffer
+	result, err := encodeAndProcess(v.client, img, &buf)
+	if err != nil {
+		result = &OCRResult{Error: err.Error()}
+	}
+	return &VideoResult{OCRResult: result, Timestamp: timestamp, Region: region}
+}
+
+// encodeAndProcess PNG-encodes img into buf and OCRs the resulting bytes.
+func encodeAndProcess(client *OCRClient, img image.Image, buf *bytes.Buffer) (*OCRResult, error) {
+	if err := png.Encode(buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode frame: %v", err)
+	}
+	return client.ProcessImageBytes(buf.Bytes())
+}
+
+// sendResult delivers result on results, or returns ctx.Err() if ctx is
+// done first.
+func sendResult(ctx context.Context, results chan<- *VideoResult, result *VideoResult) error {
+	select {
+	case results <- result:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+package haseb
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// encodeFrame PNG-encodes a solid img.Rect(0, 0, size, size) gray image,
+// matching the frame format ffmpeg's image2pipe/png muxer produces.
+func encodeFrame(t *testing.T, size int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// noopCmd returns a started, already-exited command so consumeFrames'
+// cmd.Wait() has something real to wait on without needing ffmpeg.
+func noopCmd(t *testing.T) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command("true")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start noop command: %v", err)
+	}
+	return cmd
+}
+
+func TestVideoOCRConsumeFramesWholeFrame(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeFrame(t, 4))
+	stream.Write(encodeFrame(t, 4))
+
+	v := &VideoOCR{client: &OCRClient{client: &fakeEngine{}}, fps: 2}
+
+	results := make(chan *VideoResult)
+	go v.consumeFrames(context.Background(), noopCmd(t), &stream, results)
+
+	var got []*VideoResult
+	for r := range results {
+		got = append(got, r)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want 2", len(got))
+	}
+	for i, r := range got {
+		if r.Region != "" {
+			t.Errorf("result %d: Region = %q, want empty (no regions configured)", i, r.Region)
+		}
+		wantTimestamp := time.Duration(float64(i) / v.fps * float64(time.Second))
+		if r.Timestamp != wantTimestamp {
+			t.Errorf("result %d: Timestamp = %v, want %v", i, r.Timestamp, wantTimestamp)
+		}
+		if r.Error != "" {
+			t.Errorf("result %d: unexpected OCR error: %s", i, r.Error)
+		}
+	}
+}
+
+func TestVideoOCRConsumeFramesPerRegion(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeFrame(t, 4))
+
+	regions := []Region{
+		{Name: "top-left", Rect: image.Rect(0, 0, 2, 2)},
+		{Name: "bottom-right", Rect: image.Rect(2, 2, 4, 4)},
+	}
+	v := &VideoOCR{client: &OCRClient{client: &fakeEngine{}}, fps: 1, regions: regions}
+
+	results := make(chan *VideoResult)
+	go v.consumeFrames(context.Background(), noopCmd(t), &stream, results)
+
+	var got []*VideoResult
+	for r := range results {
+		got = append(got, r)
+	}
+
+	if len(got) != len(regions) {
+		t.Fatalf("got %d results, want %d (one per region)", len(got), len(regions))
+	}
+	for i, region := range regions {
+		if got[i].Region != region.Name {
+			t.Errorf("result %d: Region = %q, want %q", i, got[i].Region, region.Name)
+		}
+	}
+}
+
+func TestVideoOCRConsumeFramesStopsOnCancel(t *testing.T) {
+	// A stream much longer than what we expect consumeFrames to actually
+	// process once ctx is canceled.
+	var stream bytes.Buffer
+	for i := 0; i < 100; i++ {
+		stream.Write(encodeFrame(t, 4))
+	}
+
+	v := &VideoOCR{client: &OCRClient{client: &fakeEngine{}}, fps: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := make(chan *VideoResult)
+	go v.consumeFrames(ctx, noopCmd(t), &stream, results)
+
+	// consumeFrames must stop (closing results) soon after an already
+	// canceled ctx, rather than draining the whole 100-frame stream.
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-results:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for results channel to close after ctx was canceled")
+		}
+	}
+}
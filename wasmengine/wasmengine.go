@@ -0,0 +1,232 @@
+// Package wasmengine implements a pure-Go OCR backend by running Tesseract
+// compiled to WebAssembly under wazero (https://wazero.io), so that
+// binaries built against this module don't need a working
+// libtesseract/libleptonica toolchain. It satisfies the same method set
+// as the CGo gosseract client so it can be plugged into an OCRClient via
+// an EngineFactory.
+package wasmengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// Client is a WASM-backed OCR engine. It is not safe for concurrent use;
+// callers needing concurrency should construct one Client per worker, the
+// same way OCRPool does with the CGo engine.
+type Client struct {
+	ctx      context.Context
+	runtime  wazero.Runtime
+	module   api.Module
+	malloc   api.Function
+	free     api.Function
+	setImage api.Function
+	setLang  api.Function
+	getText  api.Function
+	getHOCR  api.Function
+
+	mutex   sync.Mutex
+	langs   string
+	hostDir string
+}
+
+// Traineddata pairs a Tesseract language code with a reader for its
+// .traineddata contents (a caller-supplied source, e.g. a //go:embed'd
+// file or one downloaded at startup).
+type Traineddata struct {
+	Lang   string
+	Reader io.Reader
+}
+
+// New instantiates the Tesseract WASM module from wasmBinary, stages every
+// entry of traineddata into a host directory mounted into the module at
+// the fixed guest path /tessdata, and activates all of their languages.
+// Passing only some of the languages an OCRClient.WithLanguages call
+// expects leaves SetLanguage failing for the rest, so callers building an
+// OCRClient with WithEngineFactory must stage every language they intend
+// to pass to WithLanguages (or rely on the "eng", "ara" default).
+func New(ctx context.Context, wasmBinary []byte, traineddata ...Traineddata) (*Client, error) {
+	if len(traineddata) == 0 {
+		return nil, fmt.Errorf("at least one language's traineddata is required")
+	}
+
+	hostDir, err := os.MkdirTemp("", "wasmengine-tessdata-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tessdata staging dir: %v", err)
+	}
+
+	langs := make([]string, 0, len(traineddata))
+	for _, td := range traineddata {
+		data, err := io.ReadAll(td.Reader)
+		if err != nil {
+			os.RemoveAll(hostDir)
+			return nil, fmt.Errorf("failed to read traineddata for %q: %v", td.Lang, err)
+		}
+		if err := os.WriteFile(hostDir+"/"+td.Lang+".traineddata", data, 0o644); err != nil {
+			os.RemoveAll(hostDir)
+			return nil, fmt.Errorf("failed to stage traineddata for %q: %v", td.Lang, err)
+		}
+		langs = append(langs, td.Lang)
+	}
+
+	runtime := wazero.NewRuntime(ctx)
+
+	fsConfig := wazero.NewFSConfig().WithDirMount(hostDir, "/tessdata")
+	moduleConfig := wazero.NewModuleConfig().WithFSConfig(fsConfig)
+
+	module, err := runtime.InstantiateWithConfig(ctx, wasmBinary, moduleConfig)
+	if err != nil {
+		runtime.Close(ctx)
+		os.RemoveAll(hostDir)
+		return nil, fmt.Errorf("failed to instantiate tesseract WASM module: %v", err)
+	}
+
+	c := &Client{
+		ctx:      ctx,
+		runtime:  runtime,
+		module:   module,
+		malloc:   module.ExportedFunction("malloc"),
+		free:     module.ExportedFunction("free"),
+		setImage: module.ExportedFunction("tess_set_image"),
+		setLang:  module.ExportedFunction("tess_set_language"),
+		getText:  module.ExportedFunction("tess_get_text"),
+		getHOCR:  module.ExportedFunction("tess_get_hocr_text"),
+		hostDir:  hostDir,
+	}
+
+	if err := c.SetLanguage(langs...); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// SetImage loads the image at imagePath from the host filesystem into the
+// module and selects it as the current image.
+func (c *Client) SetImage(imagePath string) error {
+	data, err := os.ReadFile(imagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read image: %v", err)
+	}
+	return c.SetImageFromBytes(data)
+}
+
+// SetImageFromBytes copies data into the module's linear memory and
+// selects it as the current image.
+func (c *Client) SetImageFromBytes(data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ptr, size, err := c.copyIntoGuest(data)
+	if err != nil {
+		return err
+	}
+	defer c.freeGuest(ptr)
+
+	if _, err := c.setImage.Call(c.ctx, ptr, size); err != nil {
+		return fmt.Errorf("tess_set_image failed: %v", err)
+	}
+	return nil
+}
+
+// SetLanguage switches the active recognition language(s).
+func (c *Client) SetLanguage(langs ...string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	joined := joinLangs(langs)
+	ptr, size, err := c.copyIntoGuest([]byte(joined))
+	if err != nil {
+		return err
+	}
+	defer c.freeGuest(ptr)
+
+	if _, err := c.setLang.Call(c.ctx, ptr, size); err != nil {
+		return fmt.Errorf("tess_set_language failed: %v", err)
+	}
+	c.langs = joined
+	return nil
+}
+
+// Text runs recognition on the current image and returns plain text.
+func (c *Client) Text() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.readGuestString(c.getText)
+}
+
+// HOCRText runs recognition on the current image and returns hOCR markup.
+func (c *Client) HOCRText() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.readGuestString(c.getHOCR)
+}
+
+// Close releases the wazero runtime and the host staging directory created
+// by New.
+func (c *Client) Close() error {
+	err := c.runtime.Close(c.ctx)
+	if c.hostDir != "" {
+		os.RemoveAll(c.hostDir)
+	}
+	return err
+}
+
+// readGuestString calls fn with no arguments, interpreting its two i32
+// return values as a (pointer, length) pair into guest memory.
+func (c *Client) readGuestString(fn api.Function) (string, error) {
+	results, err := fn.Call(c.ctx)
+	if err != nil {
+		return "", fmt.Errorf("WASM call failed: %v", err)
+	}
+	ptr, size := uint32(results[0]), uint32(results[1])
+	defer c.freeGuest(uint64(ptr))
+
+	buf, ok := c.module.Memory().Read(ptr, size)
+	if !ok {
+		return "", fmt.Errorf("failed to read result from guest memory")
+	}
+	return string(buf), nil
+}
+
+// copyIntoGuest allocates len(data) bytes in guest memory via the
+// module's malloc export and writes data into it, returning the pointer
+// and length to pass to a WASM call.
+func (c *Client) copyIntoGuest(data []byte) (ptr uint64, size uint64, err error) {
+	results, err := c.malloc.Call(c.ctx, uint64(len(data)))
+	if err != nil {
+		return 0, 0, fmt.Errorf("guest malloc failed: %v", err)
+	}
+	ptr = results[0]
+
+	if !c.module.Memory().Write(uint32(ptr), data) {
+		return 0, 0, fmt.Errorf("failed to write to guest memory")
+	}
+	return ptr, uint64(len(data)), nil
+}
+
+// freeGuest releases memory previously returned by copyIntoGuest or a
+// guest allocation.
+func (c *Client) freeGuest(ptr uint64) {
+	_, _ = c.free.Call(c.ctx, ptr)
+}
+
+// joinLangs renders langs the way tesseract's "+"-separated language
+// strings expect (e.g. "eng+ara").
+func joinLangs(langs []string) string {
+	joined := ""
+	for i, l := range langs {
+		if i > 0 {
+			joined += "+"
+		}
+		joined += l
+	}
+	return joined
+}
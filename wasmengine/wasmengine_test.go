@@ -0,0 +1,329 @@
+package wasmengine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The real Tesseract WASM binary this package targets isn't available in
+// this repo (it's supplied by the caller at runtime), so these tests
+// exercise Client against a small hand-assembled WASM module implementing
+// just enough of the malloc/free/tess_* export surface to drive
+// copyIntoGuest, freeGuest, readGuestString, and the multi-language
+// staging path in New.
+//
+// The fixture's malloc always returns a fixed pointer (fixtureInputPtr)
+// regardless of the requested size, and tess_set_image/tess_set_language
+// are no-ops; tess_get_text/tess_get_hocr_text return fixed (ptr, size)
+// pairs into data segments preloaded with fixtureTextData/fixtureHOCRData.
+// That's enough to verify the host-side memory plumbing without needing a
+// real Tesseract guest.
+const (
+	fixtureInputPtr = 0
+	fixtureTextPtr  = 2048
+	fixtureTextData = "wasm-engine-fixture-text"
+	fixtureHOCRPtr  = 4096
+	fixtureHOCRData = "<fixture-hocr/>"
+)
+
+// uleb128 encodes v as unsigned LEB128, the integer encoding the WASM
+// binary format uses throughout (section/vector lengths, indices).
+func uleb128(v uint32) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v != 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if v == 0 {
+			return out
+		}
+	}
+}
+
+// sleb128 encodes v as signed LEB128, used for i32.const immediates.
+func sleb128(v int64) []byte {
+	var out []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		done := (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0)
+		if !done {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if done {
+			return out
+		}
+	}
+}
+
+// wasmSection wraps content in a section header: id, then its byte length
+// as uleb128, then the content itself.
+func wasmSection(id byte, content []byte) []byte {
+	out := []byte{id}
+	out = append(out, uleb128(uint32(len(content)))...)
+	return append(out, content...)
+}
+
+// vec encodes a WASM vector: a uleb128 count followed by the concatenated
+// items.
+func vec(count int, items ...[]byte) []byte {
+	out := uleb128(uint32(count))
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+// buildFixtureModule hand-assembles a minimal WASM binary exporting the
+// six functions Client.New/Client methods call (malloc, free,
+// tess_set_image, tess_set_language, tess_get_text, tess_get_hocr_text)
+// plus one page of linear memory preloaded with fixtureTextData and
+// fixtureHOCRData at fixtureTextPtr/fixtureHOCRPtr.
+func buildFixtureModule(t *testing.T) []byte {
+	t.Helper()
+
+	const i32 = 0x7f
+	funcType := func(params, results []byte) []byte {
+		out := []byte{0x60}
+		out = append(out, vec(len(params), asItems(params)...)...)
+		out = append(out, vec(len(results), asItems(results)...)...)
+		return out
+	}
+
+	// Type section: 0=(i32)->(i32) malloc, 1=(i32)->() free,
+	// 2=(i32,i32)->() tess_set_image/tess_set_language,
+	// 3=()->(i32,i32) tess_get_text/tess_get_hocr_text.
+	types := vec(4,
+		funcType([]byte{i32}, []byte{i32}),
+		funcType([]byte{i32}, nil),
+		funcType([]byte{i32, i32}, nil),
+		funcType(nil, []byte{i32, i32}),
+	)
+
+	// Function section: one entry per function, naming its type index.
+	functions := vec(6,
+		uleb128(0), // malloc
+		uleb128(1), // free
+		uleb128(2), // tess_set_image
+		uleb128(2), // tess_set_language
+		uleb128(3), // tess_get_text
+		uleb128(3), // tess_get_hocr_text
+	)
+
+	// Memory section: one memory, 1 page (64KiB) minimum, no maximum.
+	memory := vec(1, append([]byte{0x00}, uleb128(1)...))
+
+	exportFunc := func(name string, index uint32) []byte {
+		out := uleb128(uint32(len(name)))
+		out = append(out, name...)
+		out = append(out, 0x00) // func export kind
+		out = append(out, uleb128(index)...)
+		return out
+	}
+	exports := vec(7,
+		exportFunc("malloc", 0),
+		exportFunc("free", 1),
+		exportFunc("tess_set_image", 2),
+		exportFunc("tess_set_language", 3),
+		exportFunc("tess_get_text", 4),
+		exportFunc("tess_get_hocr_text", 5),
+		append(append(uleb128(uint32(len("memory"))), "memory"...), 0x02, 0x00),
+	)
+
+	// codeEntry builds one length-prefixed function body: zero local-decl
+	// groups, then instrs, then the end opcode.
+	codeEntry := func(instrs ...byte) []byte {
+		b := []byte{0x00}
+		b = append(b, instrs...)
+		b = append(b, 0x0b)
+		out := uleb128(uint32(len(b)))
+		return append(out, b...)
+	}
+
+	i32Const := func(v int64) []byte {
+		return append([]byte{0x41}, sleb128(v)...)
+	}
+
+	code := vec(6,
+		codeEntry(i32Const(fixtureInputPtr)...), // malloc -> fixed ptr
+		codeEntry(),                             // free -> no-op
+		codeEntry(),                             // tess_set_image -> no-op
+		codeEntry(),                             // tess_set_language -> no-op
+		codeEntry(append(i32Const(fixtureTextPtr), i32Const(int64(len(fixtureTextData)))...)...),
+		codeEntry(append(i32Const(fixtureHOCRPtr), i32Const(int64(len(fixtureHOCRData)))...)...),
+	)
+
+	dataSegment := func(offset int64, data string) []byte {
+		out := uleb128(0) // memory index 0
+		out = append(out, i32Const(offset)...)
+		out = append(out, 0x0b) // end of offset expr
+		out = append(out, uleb128(uint32(len(data)))...)
+		return append(out, data...)
+	}
+	data := vec(2,
+		dataSegment(fixtureTextPtr, fixtureTextData),
+		dataSegment(fixtureHOCRPtr, fixtureHOCRData),
+	)
+
+	module := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00} // \0asm + version 1
+	module = append(module, wasmSection(1, types)...)
+	module = append(module, wasmSection(3, functions)...)
+	module = append(module, wasmSection(5, memory)...)
+	module = append(module, wasmSection(7, exports)...)
+	module = append(module, wasmSection(10, code)...)
+	module = append(module, wasmSection(11, data)...)
+	return module
+}
+
+// asItems splits a []byte of valtypes into individual single-byte slices,
+// the shape vec() expects.
+func asItems(valtypes []byte) [][]byte {
+	items := make([][]byte, len(valtypes))
+	for i, v := range valtypes {
+		items[i] = []byte{v}
+	}
+	return items
+}
+
+func newFixtureClient(t *testing.T, langs ...string) *Client {
+	t.Helper()
+	if len(langs) == 0 {
+		langs = []string{"eng"}
+	}
+	traineddata := make([]Traineddata, len(langs))
+	for i, lang := range langs {
+		traineddata[i] = Traineddata{Lang: lang, Reader: strings.NewReader("fake-traineddata-" + lang)}
+	}
+
+	client, err := New(context.Background(), buildFixtureModule(t), traineddata...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestNewStagesMultipleLanguages(t *testing.T) {
+	client := newFixtureClient(t, "eng", "fra")
+
+	for _, lang := range []string{"eng", "fra"} {
+		path := filepath.Join(client.hostDir, lang+".traineddata")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("staged traineddata for %q: %v", lang, err)
+		}
+		want := "fake-traineddata-" + lang
+		if string(data) != want {
+			t.Fatalf("staged traineddata for %q = %q, want %q", lang, data, want)
+		}
+	}
+
+	if client.langs != "eng+fra" {
+		t.Fatalf("client.langs = %q, want %q", client.langs, "eng+fra")
+	}
+}
+
+func TestCopyIntoGuestAndFreeGuest(t *testing.T) {
+	client := newFixtureClient(t)
+
+	want := []byte("hello guest memory")
+	ptr, size, err := client.copyIntoGuest(want)
+	if err != nil {
+		t.Fatalf("copyIntoGuest: %v", err)
+	}
+	if size != uint64(len(want)) {
+		t.Fatalf("size = %d, want %d", size, len(want))
+	}
+
+	got, ok := client.module.Memory().Read(uint32(ptr), uint32(size))
+	if !ok {
+		t.Fatal("failed to read back guest memory")
+	}
+	if string(got) != string(want) {
+		t.Fatalf("guest memory = %q, want %q", got, want)
+	}
+
+	client.freeGuest(ptr) // exercises the free export; no-op fixture, so just must not panic
+}
+
+func TestReadGuestString(t *testing.T) {
+	client := newFixtureClient(t)
+
+	text, err := client.readGuestString(client.getText)
+	if err != nil {
+		t.Fatalf("readGuestString(getText): %v", err)
+	}
+	if text != fixtureTextData {
+		t.Fatalf("Text = %q, want %q", text, fixtureTextData)
+	}
+
+	hocr, err := client.readGuestString(client.getHOCR)
+	if err != nil {
+		t.Fatalf("readGuestString(getHOCR): %v", err)
+	}
+	if hocr != fixtureHOCRData {
+		t.Fatalf("HOCRText = %q, want %q", hocr, fixtureHOCRData)
+	}
+}
+
+func TestSetImageFromBytesWritesToGuestMemory(t *testing.T) {
+	client := newFixtureClient(t)
+
+	want := []byte("a fake PNG's worth of bytes")
+	if err := client.SetImageFromBytes(want); err != nil {
+		t.Fatalf("SetImageFromBytes: %v", err)
+	}
+
+	// malloc's fixture always returns fixtureInputPtr, so copyIntoGuest
+	// must have written want there before calling tess_set_image.
+	got, ok := client.module.Memory().Read(fixtureInputPtr, uint32(len(want)))
+	if !ok {
+		t.Fatal("failed to read back guest memory")
+	}
+	if string(got) != string(want) {
+		t.Fatalf("guest memory at input ptr = %q, want %q", got, want)
+	}
+}
+
+func TestClientTextAndHOCRText(t *testing.T) {
+	client := newFixtureClient(t)
+
+	text, err := client.Text()
+	if err != nil {
+		t.Fatalf("Text: %v", err)
+	}
+	if text != fixtureTextData {
+		t.Fatalf("Text = %q, want %q", text, fixtureTextData)
+	}
+
+	hocr, err := client.HOCRText()
+	if err != nil {
+		t.Fatalf("HOCRText: %v", err)
+	}
+	if hocr != fixtureHOCRData {
+		t.Fatalf("HOCRText = %q, want %q", hocr, fixtureHOCRData)
+	}
+}
+
+func TestJoinLangs(t *testing.T) {
+	cases := []struct {
+		langs []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"eng"}, "eng"},
+		{[]string{"eng", "ara"}, "eng+ara"},
+	}
+	for _, c := range cases {
+		if got := joinLangs(c.langs); got != c.want {
+			t.Errorf("joinLangs(%v) = %q, want %q", c.langs, got, c.want)
+		}
+	}
+}